@@ -0,0 +1,126 @@
+package linkchecker
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+)
+
+// credential is a header or credentials applied only to requests for its
+// hosts, or to the site being checked when no hosts are given
+type credential struct {
+	hosts []string
+	apply func(*http.Request)
+}
+
+// WithHeader sets a header on requests for hosts, or for the site being
+// checked when no hosts are given, so headers such as authorization or
+// cookie are never sent to external links
+func WithHeader(key, value string, hosts ...string) Option {
+	return func(l *LinkChecker) error {
+		l.credentials = append(l.credentials, credential{
+			hosts: hosts,
+			apply: func(r *http.Request) {
+				r.Header.Set(key, value)
+			},
+		})
+		return nil
+	}
+}
+
+// WithCookieJar sets the cookie jar of HTTPClient, so set HTTPClient
+// before applying it rather than replacing it afterwards
+func WithCookieJar(jar http.CookieJar) Option {
+	return func(l *LinkChecker) error {
+		l.HTTPClient.Jar = jar
+		return nil
+	}
+}
+
+func WithBasicAuth(username, password string, hosts ...string) Option {
+	return func(l *LinkChecker) error {
+		l.credentials = append(l.credentials, credential{
+			hosts: hosts,
+			apply: func(r *http.Request) {
+				r.SetBasicAuth(username, password)
+			},
+		})
+		return nil
+	}
+}
+
+func WithBearerToken(token string, hosts ...string) Option {
+	return func(l *LinkChecker) error {
+		l.credentials = append(l.credentials, credential{
+			hosts: hosts,
+			apply: func(r *http.Request) {
+				r.Header.Set("authorization", "Bearer "+token)
+			},
+		})
+		return nil
+	}
+}
+
+func (c credential) matches(u *url.URL, domain string) bool {
+
+	if len(c.hosts) == 0 {
+		return strings.EqualFold(u.Host, domain)
+	}
+
+	for _, host := range c.hosts {
+		if strings.EqualFold(u.Host, host) || strings.EqualFold(u.Hostname(), host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *LinkChecker) newRequest(method, link string, body io.Reader) (*http.Request, error) {
+
+	request, err := http.NewRequest(method, link, body)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("user-agent", "linkchecker")
+	request.Header.Set("accept", "*/*")
+
+	for _, c := range l.credentials {
+		if c.matches(request.URL, l.Domain) {
+			c.apply(request)
+		}
+	}
+
+	return request, nil
+}
+
+// cookieJarFor returns a cookie jar holding name=value cookies for site
+func cookieJarFor(site string, cookies []string) (http.CookieJar, error) {
+
+	u, err := url.Parse(site)
+	if err != nil {
+		return nil, err
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []*http.Cookie{}
+	for _, cookie := range cookies {
+		parts := strings.SplitN(cookie, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid cookie %q, expected name=value", cookie)
+		}
+		list = append(list, &http.Cookie{Name: parts[0], Value: parts[1]})
+	}
+
+	jar.SetCookies(u, list)
+
+	return jar, nil
+}
@@ -0,0 +1,124 @@
+package linkchecker_test
+
+import (
+	"linkchecker"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+func TestCredentialsOnlySentToSite(t *testing.T) {
+	t.Parallel()
+
+	var mutex sync.Mutex
+	externalAuth := []string{}
+	externalHeaders := []string{}
+
+	external := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		externalAuth = append(externalAuth, r.Header.Get("authorization"))
+		externalHeaders = append(externalHeaders, r.Header.Get("x-team"))
+	}))
+
+	site := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "docs" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("x-team") != "platform" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`<a href="/private">private</a><a href="` + external.URL + `">external</a>`))
+	}))
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithBasicAuth("docs", "secret"),
+		linkchecker.WithHeader("x-team", "platform"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = site.Client()
+
+	err = l.Check(site.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := l.GetAllResults()
+	if len(got) != 0 {
+		t.Fatalf("want no broken links, got: %v", got)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if len(externalAuth) == 0 {
+		t.Fatal("want external link to be requested")
+	}
+
+	for i := range externalAuth {
+		if externalAuth[i] != "" {
+			t.Fatalf("credentials sent to external link: %q", externalAuth[i])
+		}
+		if externalHeaders[i] != "" {
+			t.Fatalf("extra header sent to external link: %q", externalHeaders[i])
+		}
+	}
+
+}
+
+func TestBearerTokenConfiguredHosts(t *testing.T) {
+	t.Parallel()
+
+	var mutex sync.Mutex
+	tokens := map[string]string{}
+
+	api := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		tokens["api"] = r.Header.Get("authorization")
+	}))
+
+	site := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		tokens["site"] = r.Header.Get("authorization")
+		mutex.Unlock()
+		w.Write([]byte(`<a href="` + api.URL + `/docs">api docs</a>`))
+	}))
+
+	u, err := url.Parse(api.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithBearerToken("abc123", u.Host),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = site.Client()
+
+	err = l.Check(site.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if tokens["api"] != "Bearer abc123" {
+		t.Fatalf("want token sent to configured host, got: %q", tokens["api"])
+	}
+
+	if tokens["site"] != "" {
+		t.Fatalf("want no token sent to site when hosts are configured, got: %q", tokens["site"])
+	}
+
+}
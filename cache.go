@@ -0,0 +1,138 @@
+package linkchecker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ResultCache is a file backed store of external link outcomes so that
+// links checked recently are not requested again on the next run
+type ResultCache struct {
+	path       string
+	successTTL time.Duration
+	failureTTL time.Duration
+
+	mutex   sync.RWMutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	ResponseCode int       `json:"response_code"`
+	Problem      string    `json:"problem"`
+	Status       Status    `json:"status"`
+	CheckedAt    time.Time `json:"checked_at"`
+}
+
+func NewResultCache(path string, successTTL, failureTTL time.Duration) (*ResultCache, error) {
+
+	cache := &ResultCache{
+		path:       path,
+		successTTL: successTTL,
+		failureTTL: failureTTL,
+		entries:    make(map[string]cacheEntry),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read result cache %s, %s", path, err)
+	}
+
+	err = json.Unmarshal(data, &cache.entries)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse result cache %s, %s", path, err)
+	}
+
+	return cache, nil
+}
+
+// Get returns the cached result for link if it has not expired
+func (c *ResultCache) Get(link string) (Result, bool) {
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	entry, ok := c.entries[link]
+	if !ok {
+		return Result{}, false
+	}
+
+	ttl := c.failureTTL
+	if entry.Status == StatusUp {
+		ttl = c.successTTL
+	}
+
+	if time.Since(entry.CheckedAt) > ttl {
+		return Result{}, false
+	}
+
+	return Result{
+		ResponseCode: entry.ResponseCode,
+		Url:          link,
+		Problem:      entry.Problem,
+		Status:       entry.Status,
+		Cached:       true,
+	}, true
+}
+
+func (c *ResultCache) Put(result Result) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[result.Url] = cacheEntry{
+		ResponseCode: result.ResponseCode,
+		Problem:      result.Problem,
+		Status:       result.Status,
+		CheckedAt:    time.Now(),
+	}
+}
+
+// Save writes the cache to disk, dropping entries that have expired
+func (c *ResultCache) Save() error {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for link, entry := range c.entries {
+		ttl := c.failureTTL
+		if entry.Status == StatusUp {
+			ttl = c.successTTL
+		}
+		if time.Since(entry.CheckedAt) > ttl {
+			delete(c.entries, link)
+		}
+	}
+
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write to a temp file first so an interrupted run can't corrupt the cache
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), c.path)
+}
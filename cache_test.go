@@ -0,0 +1,169 @@
+package linkchecker_test
+
+import (
+	"linkchecker"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResultCacheExternalLinks(t *testing.T) {
+	t.Parallel()
+
+	var requests int32
+	external := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+
+	site := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<a href="` + external.URL + `/page">external</a>`))
+	}))
+
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	check := func() []linkchecker.Result {
+		l, err := linkchecker.NewLinkChecker(
+			linkchecker.WithVerboseMode(),
+			linkchecker.WithResultCache(path, time.Hour, time.Minute),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.HTTPClient = site.Client()
+
+		err = l.Check(site.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return l.GetAllResults()
+	}
+
+	check()
+
+	if atomic.LoadInt32(&requests) != 2 {
+		t.Fatalf("want 2 requests to external site on first run, got: %d", atomic.LoadInt32(&requests))
+	}
+
+	want := linkchecker.Result{
+		ResponseCode:  http.StatusOK,
+		Url:           external.URL + "/page",
+		ReferringSite: site.URL,
+		Status:        linkchecker.StatusUp,
+		Cached:        true,
+	}
+
+	var got linkchecker.Result
+	for _, result := range check() {
+		if result.Url == want.Url {
+			got = result
+		}
+	}
+
+	if atomic.LoadInt32(&requests) != 2 {
+		t.Fatalf("want no further requests to external site on second run, got: %d", atomic.LoadInt32(&requests)-2)
+	}
+
+	if !cmp.Equal(want, got, ignoreMetrics, ignoreReferrers) {
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics, ignoreReferrers))
+	}
+
+}
+
+func TestResultCacheExpiry(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	checkedAt := time.Now().Add(-2 * time.Hour).Format(time.RFC3339)
+	data := `{
+		"https://example.com/up": {"response_code": 200, "status": 1, "checked_at": "` + checkedAt + `"},
+		"https://example.com/down": {"response_code": 404, "status": 2, "checked_at": "` + checkedAt + `"}
+	}`
+
+	err := os.WriteFile(path, []byte(data), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err := linkchecker.NewResultCache(path, 24*time.Hour, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, ok := cache.Get("https://example.com/up")
+	if !ok {
+		t.Fatal("want successful result within ttl to be cached")
+	}
+
+	_, ok = cache.Get("https://example.com/down")
+	if ok {
+		t.Fatal("want failed result older than failure ttl to be expired")
+	}
+
+}
+
+func TestResultCacheSkippedLinks(t *testing.T) {
+	t.Parallel()
+
+	var requests int32
+	external := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+
+	site := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<a href="` + external.URL + `/page">external</a>`))
+	}))
+
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	check := func(opts ...linkchecker.Option) []linkchecker.Result {
+		opts = append(opts,
+			linkchecker.WithVerboseMode(),
+			linkchecker.WithResultCache(path, time.Hour, time.Minute),
+		)
+		l, err := linkchecker.NewLinkChecker(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.HTTPClient = site.Client()
+
+		err = l.Check(site.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return l.GetAllResults()
+	}
+
+	check(linkchecker.WithPolicyRules(linkchecker.PolicyRule{Pattern: "/page$", Action: linkchecker.PolicySkip}))
+
+	if atomic.LoadInt32(&requests) != 0 {
+		t.Fatalf("want skipped link not requested, got: %d requests", atomic.LoadInt32(&requests))
+	}
+
+	want := linkchecker.Result{
+		ResponseCode:  http.StatusOK,
+		Url:           external.URL + "/page",
+		ReferringSite: site.URL,
+		Status:        linkchecker.StatusUp,
+	}
+
+	var got linkchecker.Result
+	for _, result := range check() {
+		if result.Url == want.Url {
+			got = result
+		}
+	}
+
+	if !cmp.Equal(want, got, ignoreMetrics, ignoreReferrers) {
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics, ignoreReferrers))
+	}
+
+}
@@ -0,0 +1,190 @@
+package linkchecker
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// CertificateInfo holds the details of the certificate presented by a host
+type CertificateInfo struct {
+	Host      string    `json:"host"`
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	DNSNames  []string  `json:"dns_names"`
+	Chain     []string  `json:"chain"`
+	Valid     bool      `json:"valid"`
+	Problem   string    `json:"problem,omitempty"`
+}
+
+// WithInsecure skips verifying certificates so that pages on hosts with
+// invalid certificates are still checked, verifying them separately to
+// warn about them instead.  It configures the transport of HTTPClient, so
+// set HTTPClient before applying it rather than replacing it afterwards.
+func WithInsecure() Option {
+	return func(l *LinkChecker) error {
+		l.insecure = true
+		t := l.transport()
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		t.TLSClientConfig.InsecureSkipVerify = true
+		return nil
+	}
+}
+
+// WithCertificateExpiryWarning warns about certificates that expire
+// within window
+func WithCertificateExpiryWarning(window time.Duration) Option {
+	return func(l *LinkChecker) error {
+		l.certExpiryWindow = window
+		return nil
+	}
+}
+
+// transport returns the http.Transport used by HTTPClient, installing a
+// clone of the default transport if a custom one is not already set
+func (l *LinkChecker) transport() *http.Transport {
+
+	t, ok := l.HTTPClient.Transport.(*http.Transport)
+	if !ok || t == nil {
+		t = http.DefaultTransport.(*http.Transport).Clone()
+		l.HTTPClient.Transport = t
+	}
+
+	return t
+}
+
+// Certificates returns the certificate details recorded for each host
+// requested.  Hosts whose links all came from the result cache were not
+// requested, so have none.
+func (l *LinkChecker) Certificates() []CertificateInfo {
+
+	l.certMutex.Lock()
+	defer l.certMutex.Unlock()
+
+	certificates := []CertificateInfo{}
+	for _, info := range l.certificates {
+		certificates = append(certificates, info)
+	}
+
+	sort.Slice(certificates, func(i, j int) bool { return certificates[i].Host < certificates[j].Host })
+
+	return certificates
+}
+
+// inspectCertificate records the certificate for a host the first time it
+// is seen and returns any warnings about it
+func (l *LinkChecker) inspectCertificate(host string, state *tls.ConnectionState) []string {
+
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+
+	l.certMutex.Lock()
+	defer l.certMutex.Unlock()
+
+	if _, ok := l.certificates[host]; ok {
+		return nil
+	}
+
+	leaf := state.PeerCertificates[0]
+
+	info := certificateInfo(host, state.PeerCertificates)
+	info.Valid = true
+
+	// the client has already verified the chain unless running insecure
+	if l.insecure {
+		hostname, _, err := net.SplitHostPort(host)
+		if err != nil {
+			hostname = host
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range state.PeerCertificates[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		_, err = leaf.Verify(x509.VerifyOptions{
+			DNSName:       hostname,
+			Intermediates: intermediates,
+		})
+		if err != nil {
+			info.Valid = false
+			info.Problem = err.Error()
+		}
+	}
+
+	l.certificates[host] = info
+
+	warnings := []string{}
+
+	if !info.Valid {
+		warnings = append(warnings, fmt.Sprintf("certificate for %s is not valid, %s", host, info.Problem))
+	}
+
+	if l.certExpiryWindow > 0 && time.Until(leaf.NotAfter) < l.certExpiryWindow {
+		warnings = append(warnings, fmt.Sprintf("certificate for %s expires on %s", host, leaf.NotAfter.Format("2006-01-02")))
+	}
+
+	return warnings
+}
+
+// inspectCertificateError records the certificate for a host that failed
+// verification, so hosts with invalid certificates are listed when not
+// running insecure
+func (l *LinkChecker) inspectCertificateError(host string, err error) {
+
+	if ClassifyError(err) != ErrorCertificateInvalid {
+		return
+	}
+
+	l.certMutex.Lock()
+	defer l.certMutex.Unlock()
+
+	if _, ok := l.certificates[host]; ok {
+		return
+	}
+
+	info := CertificateInfo{Host: host}
+
+	var verificationError *tls.CertificateVerificationError
+	if errors.As(err, &verificationError) {
+		info = certificateInfo(host, verificationError.UnverifiedCertificates)
+		err = verificationError.Err
+	}
+
+	info.Problem = err.Error()
+
+	l.certificates[host] = info
+}
+
+// certificateInfo describes the leaf certificate of a chain
+func certificateInfo(host string, chain []*x509.Certificate) CertificateInfo {
+
+	info := CertificateInfo{Host: host}
+	if len(chain) == 0 {
+		return info
+	}
+
+	leaf := chain[0]
+
+	info.Subject = leaf.Subject.String()
+	info.Issuer = leaf.Issuer.String()
+	info.NotBefore = leaf.NotBefore
+	info.NotAfter = leaf.NotAfter
+	info.DNSNames = leaf.DNSNames
+
+	for _, cert := range chain {
+		info.Chain = append(info.Chain, cert.Subject.String())
+	}
+
+	return info
+}
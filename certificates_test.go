@@ -0,0 +1,161 @@
+package linkchecker_test
+
+import (
+	"linkchecker"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInsecureModeFlagsInvalidCertificate(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no ts.Client(), the test server certificate is self-signed
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := l.GetAllResults()
+	if len(results) != 1 {
+		t.Fatalf("want 1 result, got: %d", len(results))
+	}
+
+	got := results[0]
+
+	if got.Status != linkchecker.StatusUp {
+		t.Fatalf("want: %s, got: %s", linkchecker.StatusUp, got.Status)
+	}
+
+	if len(got.Warnings) != 1 || !strings.Contains(got.Warnings[0], "is not valid") {
+		t.Fatalf("want invalid certificate warning, got: %q", got.Warnings)
+	}
+
+	certificates := l.Certificates()
+	if len(certificates) != 1 {
+		t.Fatalf("want 1 certificate, got: %d", len(certificates))
+	}
+
+	if certificates[0].Host != u.Host || certificates[0].Valid {
+		t.Fatalf("want invalid certificate for %s, got: %+v", u.Host, certificates[0])
+	}
+
+}
+
+func TestCertificateExpiryWarning(t *testing.T) {
+	t.Parallel()
+
+	fs := http.FileServer(http.Dir("./testdata"))
+
+	ts := httptest.NewTLSServer(fs)
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithVerboseMode(),
+		linkchecker.WithCertificateExpiryWarning(100*365*24*time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	warnings := []string{}
+	for _, result := range l.GetAllResults() {
+		warnings = append(warnings, result.Warnings...)
+	}
+
+	// certificate is only inspected once per host
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "expires on") {
+		t.Fatalf("want a single expiry warning, got: %q", warnings)
+	}
+
+	if !l.Certificates()[0].Valid {
+		t.Fatal("want certificate verified by client to be valid")
+	}
+
+}
+
+func TestSecureModeRecordsInvalidCertificate(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no ts.Client(), so the self-signed certificate fails verification
+	l, err := linkchecker.NewLinkChecker()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := l.GetAllResults()
+	if len(results) != 1 || results[0].ErrorKind != linkchecker.ErrorCertificateInvalid {
+		t.Fatalf("want 1 invalid certificate result, got: %v", results)
+	}
+
+	certificates := l.Certificates()
+	if len(certificates) != 1 {
+		t.Fatalf("want 1 certificate, got: %d", len(certificates))
+	}
+
+	got := certificates[0]
+
+	if got.Host != u.Host || got.Valid || got.Problem == "" || got.Issuer == "" || len(got.Chain) == 0 {
+		t.Fatalf("want invalid certificate details for %s, got: %+v", u.Host, got)
+	}
+
+}
+
+func TestCertificateRecordedForRateLimitedHost(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+
+	l, err := linkchecker.NewLinkChecker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certificates := l.Certificates()
+	if len(certificates) != 1 || !certificates[0].Valid {
+		t.Fatalf("want the certificate of the rate limited host, got: %+v", certificates)
+	}
+
+}
@@ -0,0 +1,183 @@
+package linkchecker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// findingMessage describes a broken link, or the warnings for a link
+// that works
+func findingMessage(result Result) string {
+
+	message := fmt.Sprintf("%s is %s", result.Url, result.Status)
+	if result.ResponseCode != 0 {
+		message += fmt.Sprintf(" (%d)", result.ResponseCode)
+	}
+	if result.Problem != "" {
+		message += ": " + result.Problem
+	}
+	for _, warning := range result.Warnings {
+		message += "\nWarning: " + warning
+	}
+
+	return message
+}
+
+// isFinding reports whether a result is shown as an annotation, which
+// is when the link is broken or has warnings
+func isFinding(result Result) bool {
+	return IsBroken(result.Status) || (result.Status == StatusUp && len(result.Warnings) > 0)
+}
+
+// NewGitHubReporter returns a reporter writing GitHub Actions workflow
+// commands, so links are annotated on the lines containing them.  Pages
+// are mapped to their source files by sources when given.
+func NewGitHubReporter(w io.Writer, sources SourceMap) Reporter {
+	return githubReporter{w: w, sources: sources}
+}
+
+type githubReporter struct {
+	w       io.Writer
+	sources SourceMap
+}
+
+func (r githubReporter) Result(result Result) error {
+	return nil
+}
+
+// Finish writes the commands once every referring page is known
+func (r githubReporter) Finish(report Report) error {
+
+	locator := newSourceLocator(r.sources)
+
+	for _, result := range report.Results {
+
+		if !isFinding(result) {
+			continue
+		}
+
+		command, title := "warning", "Link "+strings.ToLower(result.Status.String())
+		switch {
+		case result.Status == StatusDown, result.Status == StatusSoft404:
+			command, title = "error", "Broken link"
+		case result.Status == StatusUp:
+			command, title = "notice", "Link warning"
+		}
+
+		for _, referrer := range result.AllReferrers() {
+
+			location := locator.locate(result, referrer)
+
+			properties := []string{"file=" + githubEscapeProperty(location.Path)}
+			if location.Line > 0 {
+				properties = append(properties, "line="+strconv.Itoa(location.Line))
+			}
+			if location.Column > 0 {
+				properties = append(properties, "col="+strconv.Itoa(location.Column))
+			}
+			properties = append(properties, "title="+githubEscapeProperty(title))
+
+			_, err := fmt.Fprintf(r.w, "::%s %s::%s\n", command, strings.Join(properties, ","), githubEscapeData(findingMessage(result)))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+var githubEscapeData = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace
+
+var githubEscapeProperty = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace
+
+type gitlabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitlabLocation `json:"location"`
+}
+
+type gitlabLocation struct {
+	Path  string      `json:"path"`
+	Lines gitlabLines `json:"lines"`
+}
+
+type gitlabLines struct {
+	Begin int `json:"begin"`
+}
+
+// NewGitLabReporter returns a reporter writing a GitLab code quality
+// report, so links are shown on the lines containing them in merge
+// requests.  Pages are mapped to their source files by sources when
+// given.
+func NewGitLabReporter(w io.Writer, sources SourceMap) Reporter {
+	return gitlabReporter{w: w, sources: sources}
+}
+
+type gitlabReporter struct {
+	w       io.Writer
+	sources SourceMap
+}
+
+func (r gitlabReporter) Result(result Result) error {
+	return nil
+}
+
+// Finish writes the report once every referring page is known
+func (r gitlabReporter) Finish(report Report) error {
+
+	locator := newSourceLocator(r.sources)
+
+	issues := []gitlabIssue{}
+
+	for _, result := range report.Results {
+
+		if !isFinding(result) {
+			continue
+		}
+
+		checkName, severity := "link-"+strings.ToLower(strings.ReplaceAll(result.Status.String(), " ", "-")), "minor"
+		switch {
+		case result.Status == StatusDown, result.Status == StatusSoft404:
+			checkName, severity = "broken-link", "major"
+		case result.Status == StatusUp:
+			checkName, severity = "link-warning", "info"
+		}
+
+		for _, referrer := range result.AllReferrers() {
+
+			location := locator.locate(result, referrer)
+
+			// the fingerprint identifies the issue between runs
+			sum := sha256.Sum256([]byte(checkName + "\x00" + result.Url + "\x00" + referrer.Url))
+
+			line := location.Line
+			if line == 0 {
+				line = 1
+			}
+
+			issues = append(issues, gitlabIssue{
+				Description: findingMessage(result),
+				CheckName:   checkName,
+				Fingerprint: hex.EncodeToString(sum[:16]),
+				Severity:    severity,
+				Location: gitlabLocation{
+					Path:  location.Path,
+					Lines: gitlabLines{Begin: line},
+				},
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(r.w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(issues)
+}
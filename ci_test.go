@@ -0,0 +1,119 @@
+package linkchecker_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"linkchecker"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func ciReport(t *testing.T) (linkchecker.Report, linkchecker.SourceMap, string) {
+	t.Helper()
+
+	source := filepath.Join(t.TempDir(), "about.md")
+	err := os.WriteFile(source, []byte("# About\n\nSee the [guide](/guide).\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := linkchecker.Report{
+		Results: []linkchecker.Result{
+			{
+				ResponseCode:  http.StatusNotFound,
+				Url:           "https://example.com/guide",
+				ReferringSite: "https://example.com/about",
+				Problem:       "Non OK response",
+				Status:        linkchecker.StatusDown,
+				Referrers:     []linkchecker.Referrer{{Url: "https://example.com/about", Text: "guide", Line: 20, Column: 4}},
+			},
+			{
+				ResponseCode:  http.StatusOK,
+				Url:           "https://example.com/slow",
+				ReferringSite: "https://example.com/contact",
+				Status:        linkchecker.StatusUp,
+				Warnings:      []string{"response took 3s, over 1s"},
+			},
+			{
+				ResponseCode:  http.StatusOK,
+				Url:           "https://example.com/fine",
+				ReferringSite: "https://example.com/about",
+				Status:        linkchecker.StatusUp,
+			},
+		},
+	}
+
+	return report, linkchecker.SourceMap{{Prefix: "/about", Source: source}}, source
+}
+
+func TestGitHubReporter(t *testing.T) {
+	t.Parallel()
+
+	report, sources, source := ciReport(t)
+
+	var output bytes.Buffer
+
+	err := linkchecker.NewGitHubReporter(&output, sources).Finish(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "::error file=" + source + ",line=3,col=17,title=Broken link::https://example.com/guide is Down (404): Non OK response\n" +
+		"::notice file=https%3A//example.com/contact,title=Link warning::https://example.com/slow is Up (200)%0AWarning: response took 3s, over 1s\n"
+
+	if !cmp.Equal(want, output.String()) {
+		t.Fatal(cmp.Diff(want, output.String()))
+	}
+
+}
+
+func TestGitLabReporter(t *testing.T) {
+	t.Parallel()
+
+	report, sources, source := ciReport(t)
+
+	var output bytes.Buffer
+
+	err := linkchecker.NewGitLabReporter(&output, sources).Finish(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var issues []struct {
+		CheckName   string `json:"check_name"`
+		Fingerprint string `json:"fingerprint"`
+		Severity    string `json:"severity"`
+		Location    struct {
+			Path  string `json:"path"`
+			Lines struct {
+				Begin int `json:"begin"`
+			} `json:"lines"`
+		} `json:"location"`
+	}
+
+	err = json.Unmarshal(output.Bytes(), &issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(issues) != 2 {
+		t.Fatalf("want 2 issues, got: %s", output.String())
+	}
+
+	if issues[0].CheckName != "broken-link" || issues[0].Severity != "major" || issues[0].Location.Path != source || issues[0].Location.Lines.Begin != 3 {
+		t.Fatalf("want broken link on line 3 of %s, got: %+v", source, issues[0])
+	}
+
+	if issues[1].Severity != "info" || issues[1].Location.Lines.Begin != 1 {
+		t.Fatalf("want warning as info on line 1, got: %+v", issues[1])
+	}
+
+	if issues[0].Fingerprint == "" || issues[0].Fingerprint == issues[1].Fingerprint {
+		t.Fatalf("want distinct fingerprints, got: %q and %q", issues[0].Fingerprint, issues[1].Fingerprint)
+	}
+
+}
@@ -0,0 +1,113 @@
+package linkchecker
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DefaultCSVColumns are the columns written by the csv format unless
+// others are chosen
+var DefaultCSVColumns = []string{"url", "referrer", "line", "column", "text", "status", "code", "problem", "time_ms"}
+
+// csvColumns gives the value of each column for a link and the page
+// referring to it
+var csvColumns = map[string]func(Result, Referrer) string{
+	"url":          func(r Result, ref Referrer) string { return r.Url },
+	"original_url": func(r Result, ref Referrer) string { return r.OriginalUrl },
+	"referrer":     func(r Result, ref Referrer) string { return ref.Url },
+	"line":         func(r Result, ref Referrer) string { return numberString(ref.Line) },
+	"column":       func(r Result, ref Referrer) string { return numberString(ref.Column) },
+	"text":         func(r Result, ref Referrer) string { return ref.Text },
+	"status":       func(r Result, ref Referrer) string { return r.Status.String() },
+	"code":         func(r Result, ref Referrer) string { return numberString(r.ResponseCode) },
+	"problem":      func(r Result, ref Referrer) string { return r.Problem },
+	"error_kind":   func(r Result, ref Referrer) string { return r.ErrorKind.String() },
+	"cached":       func(r Result, ref Referrer) string { return strconv.FormatBool(r.Cached) },
+	"time_ms":      func(r Result, ref Referrer) string { return strconv.FormatInt(r.Metrics.Total.Milliseconds(), 10) },
+	"content_type": func(r Result, ref Referrer) string { return r.Metrics.ContentType },
+	"warnings":     func(r Result, ref Referrer) string { return strings.Join(r.Warnings, "; ") },
+}
+
+// numberString leaves unknown numbers empty rather than writing 0
+func numberString(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// spreadsheetSafe keeps text taken from pages, such as anchor text, from
+// being run as a formula when the file is opened in a spreadsheet
+func spreadsheetSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// NewCSVReporter returns a reporter writing one row per link and
+// referring page, with the given columns
+func NewCSVReporter(w io.Writer, columns ...string) (Reporter, error) {
+	return newDelimitedReporter(w, ',', columns)
+}
+
+// NewTSVReporter is NewCSVReporter with tab separated values
+func NewTSVReporter(w io.Writer, columns ...string) (Reporter, error) {
+	return newDelimitedReporter(w, '\t', columns)
+}
+
+func newDelimitedReporter(w io.Writer, comma rune, columns []string) (Reporter, error) {
+
+	if len(columns) == 0 {
+		columns = DefaultCSVColumns
+	}
+
+	for _, column := range columns {
+		if _, ok := csvColumns[column]; !ok {
+			return nil, fmt.Errorf("unknown csv column %q", column)
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+
+	return csvReporter{w: writer, columns: columns}, nil
+}
+
+type csvReporter struct {
+	w       *csv.Writer
+	columns []string
+}
+
+func (r csvReporter) Result(result Result) error {
+	return nil
+}
+
+// Finish writes the rows once every referring page is known
+func (r csvReporter) Finish(report Report) error {
+
+	err := r.w.Write(r.columns)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range report.Results {
+		for _, referrer := range result.AllReferrers() {
+			row := make([]string, len(r.columns))
+			for i, column := range r.columns {
+				row[i] = spreadsheetSafe(csvColumns[column](result, referrer))
+			}
+			err = r.w.Write(row)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	r.w.Flush()
+
+	return r.w.Error()
+}
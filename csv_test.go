@@ -0,0 +1,153 @@
+package linkchecker_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"linkchecker"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCSVReporter(t *testing.T) {
+	t.Parallel()
+
+	report := linkchecker.Report{
+		Results: []linkchecker.Result{
+			{
+				ResponseCode:  http.StatusNotFound,
+				Url:           "https://example.com/a,b",
+				ReferringSite: "https://example.com",
+				Problem:       "Non OK response",
+				Status:        linkchecker.StatusDown,
+				Metrics:       linkchecker.Metrics{Total: 1500 * time.Millisecond},
+				Referrers: []linkchecker.Referrer{
+					{Url: "https://example.com", Text: `the "a, b" page`, Line: 3, Column: 7},
+					{Url: "https://example.com/about", Text: "a, b", Line: 12, Column: 1},
+				},
+			},
+			{
+				Url:           "https://example.com/slow",
+				ReferringSite: "https://example.com",
+				Problem:       "Client.Timeout exceeded while awaiting headers",
+				Status:        linkchecker.StatusTimeout,
+			},
+		},
+	}
+
+	var output bytes.Buffer
+
+	reporter, err := linkchecker.NewCSVReporter(&output, "url", "referrer", "line", "text", "status", "code", "time_ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = reporter.Finish(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := csv.NewReader(&output).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"url", "referrer", "line", "text", "status", "code", "time_ms"},
+		{"https://example.com/a,b", "https://example.com", "3", `the "a, b" page`, "Down", "404", "1500"},
+		{"https://example.com/a,b", "https://example.com/about", "12", "a, b", "Down", "404", "1500"},
+		{"https://example.com/slow", "https://example.com", "", "", "Timeout", "", "0"},
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Fatal(cmp.Diff(want, got))
+	}
+
+	_, err = linkchecker.NewCSVReporter(&output, "url", "colour")
+	if err == nil {
+		t.Fatal("want error for unknown column")
+	}
+
+}
+
+func TestTSVReporter(t *testing.T) {
+	t.Parallel()
+
+	report := linkchecker.Report{
+		Results: []linkchecker.Result{
+			{
+				ResponseCode:  http.StatusNotFound,
+				Url:           "https://example.com/a,b",
+				ReferringSite: "https://example.com",
+				Status:        linkchecker.StatusDown,
+				Referrers:     []linkchecker.Referrer{{Url: "https://example.com", Text: "a\tb", Line: 3}},
+			},
+		},
+	}
+
+	var output bytes.Buffer
+
+	reporter, err := linkchecker.NewTSVReporter(&output, "url", "text", "code")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = reporter.Finish(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "url\ttext\tcode\nhttps://example.com/a,b\t\"a\tb\"\t404\n"
+
+	if !cmp.Equal(want, output.String()) {
+		t.Fatal(cmp.Diff(want, output.String()))
+	}
+
+}
+
+func TestCSVReporterFormulas(t *testing.T) {
+	t.Parallel()
+
+	report := linkchecker.Report{
+		Results: []linkchecker.Result{
+			{
+				ResponseCode:  http.StatusNotFound,
+				Url:           "https://example.com/sum",
+				ReferringSite: "https://example.com",
+				Problem:       "@SUM(A1:A2)",
+				Status:        linkchecker.StatusDown,
+				Referrers: []linkchecker.Referrer{
+					{Url: "https://example.com", Text: "=HYPERLINK(\"https://evil.example\")"},
+					{Url: "https://example.com/b", Text: "+1"},
+					{Url: "https://example.com/c", Text: "-1"},
+					{Url: "https://example.com/d", Text: "a=b"},
+				},
+			},
+		},
+	}
+
+	var output bytes.Buffer
+
+	reporter, err := linkchecker.NewCSVReporter(&output, "text", "problem")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = reporter.Finish(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "text,problem\n" +
+		"\"'=HYPERLINK(\"\"https://evil.example\"\")\",'@SUM(A1:A2)\n" +
+		"'+1,'@SUM(A1:A2)\n" +
+		"'-1,'@SUM(A1:A2)\n" +
+		"a=b,'@SUM(A1:A2)\n"
+
+	if !cmp.Equal(want, output.String()) {
+		t.Fatal(cmp.Diff(want, output.String()))
+	}
+
+}
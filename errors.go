@@ -0,0 +1,108 @@
+package linkchecker
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+)
+
+// ErrorKind classifies the error returned when requesting a link
+type ErrorKind int
+
+const (
+	ErrorNone ErrorKind = iota
+	ErrorUnknown
+	ErrorTimeout
+	ErrorDNSNotFound
+	ErrorConnectionRefused
+	ErrorConnectionReset
+	ErrorTLSHandshake
+	ErrorCertificateInvalid
+	ErrorTooManyRedirects
+)
+
+var ErrorKindStringMap = map[ErrorKind]string{
+	ErrorNone:               "",
+	ErrorUnknown:            "Unknown",
+	ErrorTimeout:            "Timeout",
+	ErrorDNSNotFound:        "DNS not found",
+	ErrorConnectionRefused:  "Connection refused",
+	ErrorConnectionReset:    "Connection reset",
+	ErrorTLSHandshake:       "TLS handshake failure",
+	ErrorCertificateInvalid: "Certificate invalid",
+	ErrorTooManyRedirects:   "Too many redirects",
+}
+
+func (e ErrorKind) String() string {
+	return ErrorKindStringMap[e]
+}
+
+func (e ErrorKind) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+func (e *ErrorKind) UnmarshalText(text []byte) error {
+
+	for kind, str := range ErrorKindStringMap {
+		if str == string(text) {
+			*e = kind
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown error kind %q", text)
+}
+
+var ErrTooManyRedirects = errors.New("stopped after 10 redirects")
+
+// checkRedirect matches the default policy of http.Client, but returns
+// ErrTooManyRedirects so the error can be classified
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return ErrTooManyRedirects
+	}
+	return nil
+}
+
+func ClassifyError(err error) ErrorKind {
+
+	if err == nil {
+		return ErrorNone
+	}
+
+	var dnsError *net.DNSError
+	var unknownAuthority x509.UnknownAuthorityError
+	var certificateInvalid x509.CertificateInvalidError
+	var hostnameError x509.HostnameError
+	var verificationError *tls.CertificateVerificationError
+	var recordHeaderError tls.RecordHeaderError
+	var alertError tls.AlertError
+	var opError *net.OpError
+
+	switch {
+	case errors.Is(err, ErrTooManyRedirects):
+		return ErrorTooManyRedirects
+	case errors.As(err, &unknownAuthority), errors.As(err, &certificateInvalid), errors.As(err, &hostnameError), errors.As(err, &verificationError):
+		return ErrorCertificateInvalid
+	case os.IsTimeout(err):
+		return ErrorTimeout
+	case errors.As(err, &dnsError) && dnsError.IsNotFound:
+		return ErrorDNSNotFound
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorConnectionRefused
+	case errors.Is(err, syscall.ECONNRESET):
+		return ErrorConnectionReset
+	case errors.As(err, &recordHeaderError), errors.As(err, &alertError):
+		return ErrorTLSHandshake
+	case errors.As(err, &opError) && opError.Op == "remote error":
+		// alerts sent by the server during the handshake
+		return ErrorTLSHandshake
+	}
+
+	return ErrorUnknown
+}
@@ -0,0 +1,97 @@
+package linkchecker_test
+
+import (
+	"crypto/tls"
+	"linkchecker"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClassifyError(t *testing.T) {
+	t.Parallel()
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	redirectServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	}))
+
+	// the server rejects the handshake as the client has no certificate
+	clientCertServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	clientCertServer.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	clientCertServer.StartTLS()
+
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+	}))
+
+	// grab a free port and release it so nothing is listening
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedAddr := listener.Addr().String()
+	listener.Close()
+
+	// reset every connection as soon as it is accepted
+	resetListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resetListener.Close()
+	go func() {
+		for {
+			conn, err := resetListener.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*net.TCPConn).SetLinger(0)
+			conn.Close()
+		}
+	}()
+
+	type testCase struct {
+		name   string
+		url    string
+		client *http.Client
+		want   linkchecker.ErrorKind
+	}
+
+	tcs := []testCase{
+		{name: "certificate invalid", url: tlsServer.URL, want: linkchecker.ErrorCertificateInvalid},
+		{name: "tls handshake failure", url: clientCertServer.URL, client: clientCertServer.Client(), want: linkchecker.ErrorTLSHandshake},
+		{name: "too many redirects", url: redirectServer.URL, want: linkchecker.ErrorTooManyRedirects},
+		{name: "connection refused", url: "http://" + closedAddr, want: linkchecker.ErrorConnectionRefused},
+		{name: "connection reset", url: "http://" + resetListener.Addr().String(), want: linkchecker.ErrorConnectionReset},
+		{name: "timeout", url: slowServer.URL, client: &http.Client{Timeout: 50 * time.Millisecond}, want: linkchecker.ErrorTimeout},
+		// .invalid is reserved and never resolves
+		{name: "dns not found", url: "http://linkchecker.invalid", want: linkchecker.ErrorDNSNotFound},
+	}
+
+	for _, tc := range tcs {
+
+		// default client does not trust the test server certificate
+		l, err := linkchecker.NewLinkChecker()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.client != nil {
+			l.HTTPClient = tc.client
+		}
+
+		_, err = l.HeadStatus(tc.url)
+		if err == nil {
+			t.Fatalf("%s: want error, got nil", tc.name)
+		}
+
+		got := linkchecker.ClassifyError(err)
+
+		if tc.want != got {
+			t.Fatalf("%s: want: %s, got: %s (%s)", tc.name, tc.want, got, err)
+		}
+	}
+
+}
@@ -0,0 +1,143 @@
+package linkchecker
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// resolveStaticPath maps a url path to a file in root, following the
+// index.html and clean url conventions of static site hosts
+func resolveStaticPath(root, urlPath string) (string, bool) {
+
+	clean := path.Clean("/" + urlPath)
+	name := filepath.Join(root, filepath.FromSlash(clean))
+
+	info, err := os.Stat(name)
+	if err == nil && info.IsDir() {
+		name = filepath.Join(name, "index.html")
+		info, err = os.Stat(name)
+	}
+
+	if err == nil && !info.IsDir() {
+		return name, true
+	}
+
+	// clean urls, /about is served from about.html
+	info, err = os.Stat(name + ".html")
+	if err == nil && !info.IsDir() {
+		return name + ".html", true
+	}
+
+	return "", false
+}
+
+// fileTransport serves file:// urls from a local build directory
+type fileTransport struct {
+	root string
+}
+
+func (t fileTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+
+	resp := &http.Response{
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Body:       http.NoBody,
+		Request:    request,
+	}
+
+	// links outside the build directory are not served
+	name, ok := "", false
+	if request.URL.Path == t.root || strings.HasPrefix(request.URL.Path, strings.TrimSuffix(t.root, "/")+"/") {
+		name, ok = resolveStaticPath(t.root, strings.TrimPrefix(request.URL.Path, t.root))
+	}
+
+	if !ok {
+		resp.StatusCode = http.StatusNotFound
+		resp.Status = "404 Not Found"
+		return resp, nil
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	resp.StatusCode = http.StatusOK
+	resp.Status = "200 OK"
+	resp.ContentLength = int64(len(data))
+	resp.Header.Set("content-type", contentType)
+	resp.Header.Set("content-length", strconv.Itoa(len(data)))
+
+	if request.Method != http.MethodHead {
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	return resp, nil
+}
+
+// canonicaliseFileUrl resolves links on local pages against the build
+// directory, so root relative links work as they would on the site, and
+// other relative links against the directory of the page at pageUrl
+func (l *LinkChecker) canonicaliseFileUrl(link string, pageUrl string) (string, error) {
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+
+	if u.Scheme != "" {
+		return link, nil
+	}
+
+	page, err := url.Parse(pageUrl)
+	if err != nil {
+		return "", err
+	}
+
+	u.Scheme = "file"
+
+	switch {
+	case pageUrl == "" || strings.HasPrefix(u.Path, "/"):
+		u.Path = path.Join(l.fileRoot, u.Path)
+	case u.Path == "":
+		// a fragment or query on the page itself
+		u.Path = page.Path
+	default:
+		// pages are served from directories as well as files, such as
+		// docs/ from docs/index.html
+		dir := path.Dir(page.Path)
+		info, err := os.Stat(filepath.FromSlash(page.Path))
+		if err == nil && info.IsDir() {
+			dir = page.Path
+		}
+		u.Path = path.Join(dir, u.Path)
+	}
+
+	return u.String(), nil
+}
+
+// FileUrl returns the file:// url for a local directory
+func FileUrl(dir string) (string, error) {
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	return "file://" + filepath.ToSlash(abs), nil
+}
@@ -0,0 +1,123 @@
+package linkchecker_test
+
+import (
+	"linkchecker"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckLocalDirectory(t *testing.T) {
+	t.Parallel()
+
+	external := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"index.html":      `<a href="/about">about</a><a href="docs/">docs</a><a href="` + external.URL + `">external</a>`,
+		"about.html":      `<a href="./">home</a><a href="/missing">missing</a>`,
+		"docs/index.html": `<a href="/about">about</a>`,
+	}
+
+	for name, content := range files {
+		err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	site, err := linkchecker.FileUrl(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithVerboseMode(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = external.Client()
+
+	err = l.Check(site)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []linkchecker.Result{
+		{ResponseCode: http.StatusOK, Url: site, ReferringSite: site, Status: linkchecker.StatusUp},
+		{ResponseCode: http.StatusOK, Url: site + "/about", ReferringSite: site, Status: linkchecker.StatusUp},
+		{ResponseCode: http.StatusOK, Url: site + "/docs", ReferringSite: site, Status: linkchecker.StatusUp},
+		{ResponseCode: http.StatusNotFound, Url: site + "/missing", ReferringSite: site + "/about", Status: linkchecker.StatusDown, Problem: "Non OK response"},
+		{ResponseCode: http.StatusOK, Url: external.URL, ReferringSite: site, Status: linkchecker.StatusUp},
+	}
+
+	got := l.GetAllResults()
+
+	if !cmp.Equal(want, got, ignoreMetrics, ignoreReferrers) {
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics, ignoreReferrers))
+	}
+
+}
+
+func TestCheckLocalDirectoryRelativeLinks(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"index.html":      `<a href="docs/">docs</a>`,
+		"img.png":         `png`,
+		"docs/index.html": `<a href="guide.html">guide</a><a href="../img.png">image</a><a href="missing.html">missing</a>`,
+		"docs/guide.html": `<a href="index.html#top">docs</a><a href="#usage">usage</a>`,
+	}
+
+	for name, content := range files {
+		err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	site, err := linkchecker.FileUrl(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithConfigureRatelimiter(1000, 1000),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = l.Check(site)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// links are resolved against the directory of the page they are on
+	want := []linkchecker.Result{
+		{ResponseCode: http.StatusNotFound, Url: site + "/docs/missing.html", ReferringSite: site + "/docs", Status: linkchecker.StatusDown, Problem: "Non OK response"},
+	}
+
+	got := l.GetAllResults()
+
+	if !cmp.Equal(want, got, ignoreMetrics, ignoreReferrers) {
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics, ignoreReferrers))
+	}
+
+}
@@ -5,13 +5,13 @@ go 1.17
 require (
 	github.com/antchfx/htmlquery v1.2.4
 	github.com/google/go-cmp v0.5.6
+	golang.org/x/net v0.0.0-20200421231249-e086a090c8fd
 	golang.org/x/time v0.0.0-20211116232009-f0f3c7e86c11
 )
 
 require (
 	github.com/antchfx/xpath v1.2.0 // indirect
 	github.com/golang/groupcache v0.0.0-20200121045136-8c9f03a8e57e // indirect
-	golang.org/x/net v0.0.0-20200421231249-e086a090c8fd // indirect
 	golang.org/x/text v0.3.0 // indirect
 	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543 // indirect
 )
@@ -0,0 +1,126 @@
+package linkchecker
+
+import (
+	"net/http"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Page is a page fetched during the crawl.  When the response body has
+// been read it is in Body and parsed into Document, otherwise, as for
+// error responses and most external links, both are nil.
+type Page struct {
+	Url      string
+	Response *http.Response
+	Body     []byte
+	Document *html.Node
+	Metrics  Metrics
+}
+
+// Hooks are called as the crawl progresses, so library users can build
+// their own audits.  Pages are crawled concurrently, so hooks may be
+// called from several goroutines at once.
+type Hooks interface {
+	OnPageFetched(page Page)
+	OnLinksExtracted(page Page, links []Link)
+	OnResult(result Result)
+	OnSkip(link string, reason string)
+	OnCrawlComplete(summary Summary)
+}
+
+// NopHooks implements Hooks doing nothing, embed it to implement only
+// the hooks you need
+type NopHooks struct{}
+
+func (NopHooks) OnPageFetched(page Page)                  {}
+func (NopHooks) OnLinksExtracted(page Page, links []Link) {}
+func (NopHooks) OnResult(result Result)                   {}
+func (NopHooks) OnSkip(link string, reason string)        {}
+func (NopHooks) OnCrawlComplete(summary Summary)          {}
+
+func WithHooks(hooks ...Hooks) Option {
+	return func(l *LinkChecker) error {
+		l.hooks = append(l.hooks, hooks...)
+		return nil
+	}
+}
+
+// Summary counts the results of a crawl
+type Summary struct {
+	Site      string         `json:"site"`
+	StartedAt time.Time      `json:"started_at"`
+	Duration  time.Duration  `json:"duration"`
+	Total     int            `json:"total"`
+	ByStatus  map[Status]int `json:"by_status"`
+}
+
+// Summary returns the counts of the results reported so far
+func (l *LinkChecker) Summary() Summary {
+
+	l.summaryMutex.Lock()
+	defer l.summaryMutex.Unlock()
+
+	summary := l.summary
+	summary.ByStatus = make(map[Status]int)
+	for status, count := range l.summary.ByStatus {
+		summary.ByStatus[status] = count
+	}
+
+	if summary.Duration == 0 && !summary.StartedAt.IsZero() {
+		summary.Duration = time.Since(summary.StartedAt)
+	}
+
+	return summary
+}
+
+func (l *LinkChecker) startSummary(site string) {
+
+	l.summaryMutex.Lock()
+	defer l.summaryMutex.Unlock()
+
+	l.summary = Summary{
+		Site:      site,
+		StartedAt: time.Now(),
+		ByStatus:  make(map[Status]int),
+	}
+}
+
+func (l *LinkChecker) finishSummary() {
+
+	l.summaryMutex.Lock()
+	l.summary.Duration = time.Since(l.summary.StartedAt)
+	l.summaryMutex.Unlock()
+
+	summary := l.Summary()
+	for _, h := range l.hooks {
+		h.OnCrawlComplete(summary)
+	}
+}
+
+func (l *LinkChecker) countResult(result Result) {
+
+	l.summaryMutex.Lock()
+	defer l.summaryMutex.Unlock()
+
+	if l.summary.ByStatus == nil {
+		l.summary.ByStatus = make(map[Status]int)
+	}
+
+	l.summary.Total++
+	l.summary.ByStatus[result.Status]++
+}
+
+func (l *LinkChecker) pageFetched(page Page) {
+
+	for _, h := range l.hooks {
+		h.OnPageFetched(page)
+	}
+}
+
+func (l *LinkChecker) skipped(link string, reason string) {
+
+	for _, h := range l.hooks {
+		h.OnSkip(link, reason)
+	}
+}
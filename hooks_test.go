@@ -0,0 +1,141 @@
+package linkchecker_test
+
+import (
+	"linkchecker"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/google/go-cmp/cmp"
+)
+
+// titleAudit records the title of each page using the parsed document
+type titleAudit struct {
+	linkchecker.NopHooks
+
+	mutex    sync.Mutex
+	titles   []string
+	statuses map[string]int
+	links    int
+	results  int
+	skipped  []string
+	summary  linkchecker.Summary
+}
+
+func (a *titleAudit) OnPageFetched(page linkchecker.Page) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.statuses[page.Url] = page.Response.StatusCode
+	if page.Document == nil {
+		return
+	}
+	title := htmlquery.FindOne(page.Document, "//title")
+	if title != nil {
+		a.titles = append(a.titles, htmlquery.InnerText(title))
+	}
+}
+
+func (a *titleAudit) OnLinksExtracted(page linkchecker.Page, links []linkchecker.Link) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.links += len(links)
+}
+
+func (a *titleAudit) OnResult(result linkchecker.Result) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.results++
+}
+
+func (a *titleAudit) OnSkip(link string, reason string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.skipped = append(a.skipped, link)
+}
+
+func (a *titleAudit) OnCrawlComplete(summary linkchecker.Summary) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.summary = summary
+}
+
+func TestHooks(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<title>Home</title><a href="about">about</a><a href="private">private</a>`))
+		case "/about":
+			w.Write([]byte(`<title>About</title><a href="missing">missing</a>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	audit := &titleAudit{statuses: make(map[string]int)}
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithHooks(audit),
+		linkchecker.WithPolicyRules(linkchecker.PolicyRule{Pattern: "/private$", Action: linkchecker.PolicySkip}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	audit.mutex.Lock()
+	defer audit.mutex.Unlock()
+
+	sort.Strings(audit.titles)
+
+	if !cmp.Equal([]string{"About", "Home"}, audit.titles) {
+		t.Fatal(cmp.Diff([]string{"About", "Home"}, audit.titles))
+	}
+
+	// the missing page has no body, but its response is still passed on
+	statuses := map[string]int{
+		ts.URL:              http.StatusOK,
+		ts.URL + "/about":   http.StatusOK,
+		ts.URL + "/missing": http.StatusNotFound,
+	}
+
+	if !cmp.Equal(statuses, audit.statuses) {
+		t.Fatal(cmp.Diff(statuses, audit.statuses))
+	}
+
+	if audit.links != 3 {
+		t.Fatalf("want 3 links extracted, got: %d", audit.links)
+	}
+
+	if audit.results != 4 {
+		t.Fatalf("want 4 results, got: %d", audit.results)
+	}
+
+	if !cmp.Equal([]string{ts.URL + "/private"}, audit.skipped) {
+		t.Fatal(cmp.Diff([]string{ts.URL + "/private"}, audit.skipped))
+	}
+
+	want := map[linkchecker.Status]int{
+		linkchecker.StatusUp:      2,
+		linkchecker.StatusDown:    1,
+		linkchecker.StatusSkipped: 1,
+	}
+
+	if audit.summary.Total != 4 || !cmp.Equal(want, audit.summary.ByStatus) {
+		t.Fatalf("want 4 results counted by status, got: %+v", audit.summary)
+	}
+
+	if audit.summary.Site != ts.URL || audit.summary.Duration <= 0 {
+		t.Fatalf("want site and duration in summary, got: %+v", audit.summary)
+	}
+
+}
@@ -0,0 +1,176 @@
+package linkchecker
+
+import (
+	"html/template"
+	"io"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// htmlSlowest is the number of slowest links listed in the html report
+const htmlSlowest = 20
+
+// NewHTMLReporter returns a reporter writing a single self contained
+// html page, with a summary and filterable tables of broken,
+// redirected and slow links grouped by referring page
+func NewHTMLReporter(w io.Writer) Reporter {
+	return htmlReporter{w: w}
+}
+
+type htmlReporter struct {
+	w io.Writer
+}
+
+type htmlCount struct {
+	Status Status
+	Count  int
+}
+
+type htmlData struct {
+	Report
+	Counts     []htmlCount
+	Broken     []ReferrerGroup
+	Redirected []ReferrerGroup
+	Slow       []ReferrerGroup
+}
+
+func (r htmlReporter) Result(result Result) error {
+	return nil
+}
+
+// Finish writes the page once every referring page is known
+func (r htmlReporter) Finish(report Report) error {
+
+	data := htmlData{Report: report}
+
+	for status, count := range report.Summary.ByStatus {
+		data.Counts = append(data.Counts, htmlCount{Status: status, Count: count})
+	}
+	sort.Slice(data.Counts, func(i, j int) bool { return data.Counts[i].Status < data.Counts[j].Status })
+
+	broken, redirected, timed := []Result{}, []Result{}, []Result{}
+	for _, result := range report.Results {
+		if IsBroken(result.Status) {
+			broken = append(broken, result)
+		}
+		if result.FinalUrl != "" {
+			redirected = append(redirected, result)
+		}
+		if result.Metrics.Total > 0 {
+			timed = append(timed, result)
+		}
+	}
+
+	data.Broken = GroupByReferrer(broken)
+	data.Redirected = GroupByReferrer(redirected)
+	data.Slow = GroupByReferrer(SlowestResults(timed, htmlSlowest))
+
+	return htmlTemplate.Execute(r.w, data)
+}
+
+// htmlLink allows links to local builds as well as web pages
+func htmlLink(link string) interface{} {
+
+	u, err := url.Parse(link)
+	if err == nil && (u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "file") {
+		return template.URL(link)
+	}
+
+	return link
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"link": htmlLink,
+	"round": func(d time.Duration) time.Duration {
+		if d < time.Millisecond {
+			return d.Round(time.Microsecond)
+		}
+		return d.Round(time.Millisecond)
+	},
+	"date": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04:05 MST")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>linkchecker report for {{.Metadata.Site}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.5em; }
+.cards { display: flex; flex-wrap: wrap; gap: 1em; }
+.card { border: 1px solid #ccc; border-radius: 4px; padding: 0.75em 1.25em; }
+.card .count { font-size: 1.75em; font-weight: bold; }
+.Up .count { color: #2a7d2a; }
+.Down .count, .Timeout .count { color: #c0392b; }
+table { border-collapse: collapse; width: 100%; margin-top: 0.5em; }
+th, td { border-bottom: 1px solid #ddd; padding: 0.3em 0.5em; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+td.page { font-weight: bold; }
+input { padding: 0.3em; width: 30em; max-width: 100%; }
+.empty { color: #777; }
+</style>
+</head>
+<body>
+<h1>linkchecker report for <a href="{{link .Metadata.Site}}">{{.Metadata.Site}}</a></h1>
+<p>Started {{date .Summary.StartedAt}}, took {{round .Summary.Duration}}, {{.Summary.Total}} links checked.</p>
+<div class="cards">
+{{- range .Counts}}
+<div class="card {{.Status}}"><div class="count">{{.Count}}</div>{{.Status}}</div>
+{{- end}}
+</div>
+
+<h2>Broken links</h2>
+{{- if .Broken}}
+<input type="search" placeholder="Filter broken links" data-table="broken">
+<table id="broken">
+<tr><th>Page</th><th>Line</th><th>Link</th><th>Text</th><th>Status</th><th>Code</th><th>Problem</th></tr>
+{{- range .Broken}}{{$page := .Page}}{{range .Links}}
+<tr><td class="page"><a href="{{link $page}}">{{$page}}</a></td><td>{{if .Referrer.Line}}{{.Referrer.Line}}{{end}}</td><td><a href="{{link .Result.Url}}">{{.Result.Url}}</a></td><td>{{.Referrer.Text}}</td><td>{{.Result.Status}}</td><td>{{.Result.ResponseCode}}</td><td>{{.Result.Problem}}</td></tr>
+{{- end}}{{end}}
+</table>
+{{- else}}
+<p class="empty">No broken links.</p>
+{{- end}}
+
+<h2>Redirected links</h2>
+{{- if .Redirected}}
+<input type="search" placeholder="Filter redirected links" data-table="redirected">
+<table id="redirected">
+<tr><th>Page</th><th>Line</th><th>Link</th><th>Redirected to</th></tr>
+{{- range .Redirected}}{{$page := .Page}}{{range .Links}}
+<tr><td class="page"><a href="{{link $page}}">{{$page}}</a></td><td>{{if .Referrer.Line}}{{.Referrer.Line}}{{end}}</td><td><a href="{{link .Result.Url}}">{{.Result.Url}}</a></td><td><a href="{{link .Result.FinalUrl}}">{{.Result.FinalUrl}}</a></td></tr>
+{{- end}}{{end}}
+</table>
+{{- else}}
+<p class="empty">No redirected links.</p>
+{{- end}}
+
+<h2>Slow links</h2>
+{{- if .Slow}}
+<input type="search" placeholder="Filter slow links" data-table="slow">
+<table id="slow">
+<tr><th>Page</th><th>Line</th><th>Link</th><th>Response time</th><th>Time to first byte</th></tr>
+{{- range .Slow}}{{$page := .Page}}{{range .Links}}
+<tr><td class="page"><a href="{{link $page}}">{{$page}}</a></td><td>{{if .Referrer.Line}}{{.Referrer.Line}}{{end}}</td><td><a href="{{link .Result.Url}}">{{.Result.Url}}</a></td><td>{{round .Result.Metrics.Total}}</td><td>{{round .Result.Metrics.TimeToFirstByte}}</td></tr>
+{{- end}}{{end}}
+</table>
+{{- else}}
+<p class="empty">No response times recorded.</p>
+{{- end}}
+
+<script>
+document.querySelectorAll("input[data-table]").forEach(function (input) {
+  input.addEventListener("input", function () {
+    var query = input.value.toLowerCase();
+    var rows = document.getElementById(input.dataset.table).querySelectorAll("tr");
+    for (var i = 1; i < rows.length; i++) {
+      rows[i].style.display = rows[i].textContent.toLowerCase().indexOf(query) >= 0 ? "" : "none";
+    }
+  });
+});
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,73 @@
+package linkchecker_test
+
+import (
+	"bytes"
+	"linkchecker"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTMLReporter(t *testing.T) {
+	t.Parallel()
+
+	report := linkchecker.Report{
+		Metadata: linkchecker.Metadata{Site: "https://example.com"},
+		Summary: linkchecker.Summary{
+			Site:     "https://example.com",
+			Total:    3,
+			ByStatus: map[linkchecker.Status]int{linkchecker.StatusUp: 2, linkchecker.StatusDown: 1},
+		},
+		Results: []linkchecker.Result{
+			{
+				ResponseCode:  http.StatusNotFound,
+				Url:           "https://example.com/missing",
+				ReferringSite: "https://example.com/about",
+				Problem:       "Non OK response",
+				Status:        linkchecker.StatusDown,
+				Referrers: []linkchecker.Referrer{
+					{Url: "https://example.com/about", Text: "<b>missing</b>", Line: 4, Column: 2},
+				},
+			},
+			{
+				ResponseCode:  http.StatusOK,
+				Url:           "https://example.com/old",
+				ReferringSite: "https://example.com",
+				Status:        linkchecker.StatusUp,
+				FinalUrl:      "https://example.com/new",
+				Metrics:       linkchecker.Metrics{Total: 1200 * time.Millisecond},
+			},
+		},
+	}
+
+	var output bytes.Buffer
+
+	err := linkchecker.NewHTMLReporter(&output).Finish(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := output.String()
+
+	wants := []string{
+		`<div class="card Down"><div class="count">1</div>Down</div>`,
+		`<td class="page"><a href="https://example.com/about">https://example.com/about</a></td><td>4</td><td><a href="https://example.com/missing">https://example.com/missing</a></td><td>&lt;b&gt;missing&lt;/b&gt;</td><td>Down</td><td>404</td>`,
+		`<td><a href="https://example.com/old">https://example.com/old</a></td><td><a href="https://example.com/new">https://example.com/new</a></td>`,
+		`<td>1.2s</td>`,
+	}
+
+	for _, want := range wants {
+		if !strings.Contains(got, want) {
+			t.Fatalf("want report to contain %s, got:\n%s", want, got)
+		}
+	}
+
+	// the report must open without any external assets
+	for _, external := range []string{"<link", " src="} {
+		if strings.Contains(got, external) {
+			t.Fatalf("want no external assets, found %q", external)
+		}
+	}
+
+}
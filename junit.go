@@ -0,0 +1,150 @@
+package linkchecker
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// NewJUnitReporter returns a reporter writing JUnit XML, with a test
+// suite for each referring page and a test case for each link on it.
+// Links that are Down, time out or are soft 404s fail, as do links with
+// any of the extra statuses given, such as StatusRateLimited or Status999
+func NewJUnitReporter(w io.Writer, failOn ...Status) Reporter {
+
+	failing := map[Status]bool{StatusDown: true, StatusTimeout: true, StatusSoft404: true}
+	for _, status := range failOn {
+		failing[status] = true
+	}
+
+	return junitReporter{w: w, failing: failing}
+}
+
+type junitReporter struct {
+	w       io.Writer
+	failing map[Status]bool
+}
+
+func (r junitReporter) Result(result Result) error {
+	return nil
+}
+
+// Finish writes the suites once every referring page is known
+func (r junitReporter) Finish(report Report) error {
+
+	suites := junitTestSuites{
+		Name: "linkchecker " + report.Metadata.Site,
+		Time: junitTime(report.Summary.Duration),
+	}
+
+	for _, group := range GroupByReferrer(report.Results) {
+
+		suite := junitTestSuite{Name: group.Page}
+		var suiteTime time.Duration
+
+		for _, link := range group.Links {
+
+			result := link.Result
+
+			testCase := junitTestCase{
+				Name:      result.Url,
+				Classname: group.Page,
+				Time:      junitTime(result.Metrics.Total),
+			}
+
+			switch {
+			case r.failing[result.Status]:
+				testCase.Failure = &junitFailure{
+					Message: result.Problem,
+					Type:    result.Status.String(),
+					Text:    fmt.Sprintf("Status Code: %d\nProblem: %s\nLinked at line %d, column %d %q", result.ResponseCode, result.Problem, link.Referrer.Line, link.Referrer.Column, link.Referrer.Text),
+				}
+				suite.Failures++
+			case result.Status == StatusSkipped, result.Status == StatusTrapped:
+				testCase.Skipped = &junitSkipped{Message: result.Problem}
+				suite.Skipped++
+			}
+
+			out := []string{}
+			if result.Retries > 0 {
+				out = append(out, fmt.Sprintf("retried %d times", result.Retries))
+			}
+			if result.Status != StatusUp && !r.failing[result.Status] && testCase.Skipped == nil {
+				out = append(out, fmt.Sprintf("status %s, %s", result.Status, result.Problem))
+			}
+			out = append(out, result.Warnings...)
+			testCase.SystemOut = strings.Join(out, "\n")
+
+			suite.Tests++
+			suiteTime += result.Metrics.Total
+			suite.Cases = append(suite.Cases, testCase)
+		}
+
+		suite.Time = junitTime(suiteTime)
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	_, err := io.WriteString(r.w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(r.w)
+	encoder.Indent("", "  ")
+
+	err = encoder.Encode(suites)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(r.w, "\n")
+	return err
+}
+
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
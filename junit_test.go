@@ -0,0 +1,183 @@
+package linkchecker_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"linkchecker"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJUnitReporter(t *testing.T) {
+	t.Parallel()
+
+	var flaky int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/flaky":
+			if atomic.AddInt32(&flaky, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/limited":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte(`<a href="/flaky">flaky</a><a href="/missing">missing</a><a href="/limited">limited</a>`))
+		}
+	}))
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithVerboseMode(),
+		linkchecker.WithRetries(2, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output bytes.Buffer
+
+	reporter := linkchecker.NewJUnitReporter(&output, linkchecker.StatusRateLimited)
+
+	err = reporter.Finish(l.Report(l.GetAllResults()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testCase struct {
+		Name    string `xml:"name,attr"`
+		Failure *struct {
+			Type string `xml:"type,attr"`
+		} `xml:"failure"`
+		SystemOut string `xml:"system-out"`
+	}
+
+	var suites struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name  string     `xml:"name,attr"`
+			Cases []testCase `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+
+	err = xml.Unmarshal(output.Bytes(), &suites)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if suites.Tests != 4 || suites.Failures != 2 {
+		t.Fatalf("want 4 tests and 2 failures, got: %d tests and %d failures", suites.Tests, suites.Failures)
+	}
+
+	if len(suites.Suites) != 1 || suites.Suites[0].Name != ts.URL {
+		t.Fatalf("want a single suite for %s, got: %+v", ts.URL, suites.Suites)
+	}
+
+	failures := map[string]string{}
+	out := map[string]string{}
+	for _, c := range suites.Suites[0].Cases {
+		if c.Failure != nil {
+			failures[c.Name] = c.Failure.Type
+		}
+		out[c.Name] = c.SystemOut
+	}
+
+	want := map[string]string{
+		ts.URL + "/missing": "Down",
+		ts.URL + "/limited": "RateLimited",
+	}
+
+	if !cmp.Equal(want, failures) {
+		t.Fatal(cmp.Diff(want, failures))
+	}
+
+	if out[ts.URL+"/flaky"] != "retried 1 times" {
+		t.Fatalf("want flaky link to show its retry, got: %q", out[ts.URL+"/flaky"])
+	}
+
+}
+
+func TestJUnitReporterFailingStatuses(t *testing.T) {
+	t.Parallel()
+
+	report := linkchecker.Report{}
+	for _, status := range []linkchecker.Status{
+		linkchecker.StatusUp,
+		linkchecker.StatusDown,
+		linkchecker.StatusTimeout,
+		linkchecker.StatusSoft404,
+		linkchecker.StatusRateLimited,
+		linkchecker.Status999,
+	} {
+		report.Results = append(report.Results, linkchecker.Result{
+			Url:           "https://example.com/" + status.String(),
+			ReferringSite: "https://example.com",
+			Status:        status,
+		})
+	}
+
+	failures := func(failOn ...linkchecker.Status) []string {
+		var output bytes.Buffer
+
+		err := linkchecker.NewJUnitReporter(&output, failOn...).Finish(report)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var suites struct {
+			Cases []struct {
+				Name    string    `xml:"name,attr"`
+				Failure *struct{} `xml:"failure"`
+			} `xml:"testsuite>testcase"`
+		}
+
+		err = xml.Unmarshal(output.Bytes(), &suites)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		failed := []string{}
+		for _, c := range suites.Cases {
+			if c.Failure != nil {
+				failed = append(failed, c.Name)
+			}
+		}
+		return failed
+	}
+
+	want := []string{
+		"https://example.com/Down",
+		"https://example.com/Timeout",
+		"https://example.com/Soft 404",
+	}
+
+	got := failures()
+	if !cmp.Equal(want, got) {
+		t.Fatal(cmp.Diff(want, got))
+	}
+
+	want = []string{
+		"https://example.com/Down",
+		"https://example.com/Timeout",
+		"https://example.com/Soft 404",
+		"https://example.com/Unable to verify",
+	}
+
+	got = failures(linkchecker.Status999)
+	if !cmp.Equal(want, got) {
+		t.Fatal(cmp.Diff(want, got))
+	}
+
+}
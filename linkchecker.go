@@ -1,13 +1,17 @@
 package linkchecker
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
+	"path"
 	"sort"
 	"strconv"
 	"strings"
@@ -23,7 +27,9 @@ type LinkChecker struct {
 	Domain string
 	Scheme string
 
-	// exported fields needed for testing
+	// exported fields needed for testing.  WithInsecure, WithResolve and
+	// WithCookieJar configure HTTPClient, so a client set after
+	// NewLinkChecker doesn't have them.
 	HTTPClient  *http.Client
 	ProgressBar *Bar
 
@@ -35,6 +41,47 @@ type LinkChecker struct {
 	checkLink   CheckLink
 	verboseMode bool
 	ratelimiter *rate.Limiter
+	cache       *ResultCache
+
+	insecure         bool
+	certExpiryWindow time.Duration
+	certificates     map[string]CertificateInfo
+	certMutex        sync.Mutex
+
+	credentials []credential
+
+	login           *Login
+	loginMutex      sync.Mutex
+	loginGeneration int
+
+	resolve      map[string]string
+	rewriteRules []RewriteRule
+	policyRules  []PolicyRule
+
+	soft404       bool
+	soft404Probes map[string]*soft404Probe
+	soft404Mutex  sync.Mutex
+
+	latencyThreshold time.Duration
+
+	referrers     map[string][]Referrer
+	referrerMutex sync.Mutex
+
+	traps *trapDetector
+
+	hooks        []Hooks
+	summary      Summary
+	summaryMutex sync.Mutex
+
+	middleware []Middleware
+	overrides  []RequestOverride
+
+	fileRoot string
+
+	retries    int
+	retryDelay time.Duration
+
+	templates *resultTemplates
 }
 
 type Option func(*LinkChecker) error
@@ -89,6 +136,17 @@ func WithSilentMode() Option {
 	}
 }
 
+func WithResultCache(path string, successTTL, failureTTL time.Duration) Option {
+	return func(l *LinkChecker) error {
+		cache, err := NewResultCache(path, successTTL, failureTTL)
+		if err != nil {
+			return err
+		}
+		l.cache = cache
+		return nil
+	}
+}
+
 func WithProgressBar() Option {
 	return func(l *LinkChecker) error {
 		l.ProgressBar = NewBar(
@@ -101,7 +159,7 @@ func WithProgressBar() Option {
 func NewLinkChecker(opts ...Option) (*LinkChecker, error) {
 
 	linkchecker := &LinkChecker{
-		HTTPClient:  &http.Client{Timeout: 10 * time.Second},
+		HTTPClient:  &http.Client{Timeout: 10 * time.Second, CheckRedirect: checkRedirect},
 		output:      os.Stdout,
 		errorLog:    os.Stderr,
 		ratelimiter: rate.NewLimiter(2, 2),
@@ -109,10 +167,15 @@ func NewLinkChecker(opts ...Option) (*LinkChecker, error) {
 		checkLink: CheckLink{
 			list: make(map[string]bool),
 		},
+		certificates: make(map[string]CertificateInfo),
+		referrers:    make(map[string][]Referrer),
 	}
 
 	for _, o := range opts {
-		o(linkchecker)
+		err := o(linkchecker)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return linkchecker, nil
@@ -127,16 +190,16 @@ func (l *LinkChecker) GetAllResults() []Result {
 
 	results := []Result{}
 
-	if l.verboseMode {
-		for result := range l.results {
+	for result := range l.results {
+		if l.isReportable(result) {
 			results = append(results, result)
 		}
-	} else if !l.verboseMode {
-		for result := range l.results {
-			if result.Status != StatusUp {
-				results = append(results, result)
-			}
-		}
+	}
+
+	// the crawl has finished once the channel is drained, so every
+	// referrer has been found
+	for i := range results {
+		results[i].Referrers = l.Referrers(results[i].Url)
 	}
 
 	sort.Slice(results, func(i, j int) bool { return results[i].Url < results[j].Url })
@@ -145,34 +208,99 @@ func (l *LinkChecker) GetAllResults() []Result {
 
 }
 
+// isReportable filters out healthy links unless running in verbose mode
+func (l *LinkChecker) isReportable(result Result) bool {
+	if l.verboseMode || len(result.Warnings) > 0 {
+		return true
+	}
+
+	return result.Status != StatusUp && result.Status != StatusSkipped
+}
+
 func (l *LinkChecker) Check(site string) error {
 
-	url, err := url.Parse(strings.TrimSpace(site))
+	canonicalSite, err := l.setSite(site)
 	if err != nil {
+		close(l.results)
 		return err
 	}
 
-	l.Scheme, l.Domain = url.Scheme, url.Host
-
-	canonicalSite := l.CanonicaliseUrl(site)
-
 	referringSite := canonicalSite
 
+	if l.login != nil {
+		err = l.Login()
+		if err != nil {
+			close(l.results)
+			return err
+		}
+	}
+
 	// check if progress bar enabled in LinkChecker struct
 	if l.ProgressBar != nil {
 		l.ProgressBar.Add()
 	}
 
+	l.startSummary(canonicalSite)
+
+	if l.templates != nil {
+		err = l.renderHeader()
+		if err != nil {
+			close(l.results)
+			return err
+		}
+	}
+
 	l.wg.Add(1)
 	go l.Crawl(canonicalSite, referringSite)
 	l.wg.Wait()
 
 	close(l.results)
 
+	l.finishSummary()
+
+	if l.cache != nil {
+		err = l.cache.Save()
+		if err != nil {
+			return fmt.Errorf("unable to save result cache, %s", err)
+		}
+	}
+
+	if l.templates != nil {
+		err = l.renderError()
+		if err != nil {
+			return err
+		}
+
+		err = l.renderFooter()
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 
 }
 
+// setSite records the scheme and domain of the site being checked and
+// returns its canonical url
+func (l *LinkChecker) setSite(site string) (string, error) {
+
+	url, err := url.Parse(strings.TrimSpace(site))
+	if err != nil {
+		return "", err
+	}
+
+	l.Scheme, l.Domain = url.Scheme, url.Host
+
+	// local builds are crawled from their directory
+	if url.Scheme == "file" {
+		l.fileRoot = path.Clean(url.Path)
+		site = "file://" + l.fileRoot
+	}
+
+	return l.CanonicaliseUrl(site), nil
+}
+
 func (l *LinkChecker) Crawl(site string, referringSite string) {
 
 	defer l.wg.Done()
@@ -182,6 +310,9 @@ func (l *LinkChecker) Crawl(site string, referringSite string) {
 		l.ProgressBar.Completed()
 	}
 
+	original := site
+	site = l.Rewrite(site)
+
 	if l.IsCrawled(site) {
 		return
 	}
@@ -191,31 +322,76 @@ func (l *LinkChecker) Crawl(site string, referringSite string) {
 		ReferringSite: referringSite,
 	}
 
+	if site != original {
+		result.OriginalUrl = original
+	}
+
 	l.AddSite(site)
 
 	// check if able to parse site
 	u, err := url.Parse(site)
 	if err != nil {
 		result.Problem = err.Error()
-		l.results <- result
+		l.report(result)
 		return
 	}
 
+	rule := l.matchPolicy(site)
+	if rule != nil && rule.Action == PolicySkip {
+		result.Status = StatusSkipped
+		result.Problem = "Skipped by policy rule"
+		if rule.Note != "" {
+			result.Problem = rule.Note
+		}
+		l.skipped(site, result.Problem)
+		l.report(result)
+		return
+	}
+
+	// external links checked recently are served from the cache
+	if l.cache != nil && u.Host != l.Domain {
+		cached, ok := l.cache.Get(site)
+		if ok {
+			cached.ReferringSite = referringSite
+			l.report(cached)
+			return
+		}
+	}
+
+	generation := l.sessionGeneration()
+
 	// check head request first
-	code, err := l.HeadStatus(site)
+	head, metrics, retries, err := l.headWithRetries(site)
+
+	// log in again and retry if the session expired mid crawl
+	if err == nil && u.Host == l.Domain && l.sessionExpired(site, head) {
+		err = l.relogin(generation)
+		if err == nil {
+			generation = l.sessionGeneration()
+			head, metrics, err = l.head(site)
+		}
+	}
+
+	code := head.StatusCode
+	result.Metrics = metrics
+	result.Retries = retries
 	if err != nil {
+		l.inspectCertificateError(u.Host, err)
+		result = l.errorResult(result, err)
+		result.ResponseCode = code
+		l.report(result)
+		return
+	}
 
-		if os.IsTimeout(err) {
-			//if IsTimeout(err) {
-			result.Problem = "Client.Timeout exceeded while awaiting headers"
-			result.Status = StatusRateLimited
-		} else {
-			result.Problem = err.Error()
-			result.Status = StatusDown
-		}
+	result.Warnings = append(result.Warnings, l.inspectCertificate(u.Host, head.TLS)...)
 
+	// codes such as 429 or linkedin's non standard 999 are accepted by
+	// policy rules
+	if rule.accepts(code) {
+		result.Problem = rule.Note
 		result.ResponseCode = code
-		l.results <- result
+		result.Status = StatusUp
+		l.report(result)
 		return
 	}
 
@@ -223,76 +399,176 @@ func (l *LinkChecker) Crawl(site string, referringSite string) {
 		result.Problem = "Site rate limit exceeded"
 		result.ResponseCode = code
 		result.Status = StatusRateLimited
-		l.results <- result
+		l.report(result)
 		return
 	}
 
-	// handle non stardard 999 error code for linkedin
-	// and other services
-	if code == 999 && strings.Contains(u.Host, "linkedin.com") {
-		result.Problem = "linkedin is up, but rejects http requests"
-		result.ResponseCode = code
-		result.Status = StatusUp
-		l.results <- result
-		return
-	} else if code == 999 {
+	if code == 999 {
 		result.Problem = "Non standard error returned by external service"
 		result.ResponseCode = code
 		result.Status = Status999
-		l.results <- result
+		l.report(result)
 		return
 	}
 
-	resp, err := l.GetResponse(site)
-	if err != nil {
-		//if IsTimeout(err) {
-		if os.IsTimeout(err) {
-			result.Problem = "Client.Timeout exceeded while awaiting headers"
-			result.Status = StatusRateLimited
-		} else {
-			result.Problem = err.Error()
-			result.Status = StatusDown
+	// the head request already used up its retries on this response, so
+	// the get is not retried again
+	maxRetries := l.retries
+	if retryable(code, nil) && code != http.StatusNotImplemented {
+		maxRetries = 0
+	}
+
+	resp, trace, retries, err := l.getWithRetries(site, maxRetries)
+	result.Retries += retries
+
+	// log in again and retry if the session expired mid crawl
+	if err == nil && u.Host == l.Domain && l.sessionExpired(site, resp) {
+		resp.Body.Close()
+		err = l.relogin(generation)
+		if err == nil {
+			resp, trace, err = l.get(site)
 		}
+	}
 
-		//result.ResponseCode = code
-		l.results <- result
+	if err != nil {
+		l.inspectCertificateError(u.Host, err)
+		result.Metrics = trace.done(nil, 0).withConnection(metrics)
+		result = l.errorResult(result, err)
+		l.report(result)
 		return
-
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		result.Problem = "Non OK response"
+	result.Metrics = trace.done(resp, resp.ContentLength).withConnection(metrics)
+
+	// the client follows redirects, so the request is for the final url
+	if resp.Request != nil && resp.Request.URL.String() != site {
+		result.FinalUrl = resp.Request.URL.String()
+	}
+
+	page := Page{
+		Url:      site,
+		Response: resp,
+		Metrics:  result.Metrics,
+	}
+
+	if rule.accepts(resp.StatusCode) {
+		result.Problem = rule.Note
 		result.ResponseCode = resp.StatusCode
-		result.Status = StatusDown
-		l.results <- result
+		result.Status = StatusUp
+		l.pageFetched(page)
+		l.report(result)
 		return
 	}
 
-	// external site
-	if u.Host != l.Domain {
-		result.Status = StatusUp
+	if resp.StatusCode != http.StatusOK {
+		result.Problem = "Non OK response"
 		result.ResponseCode = resp.StatusCode
-		l.results <- result
+		result.Status = StatusDown
+		l.pageFetched(page)
+		l.report(result)
 		return
 	}
 
 	result.Status = StatusUp
 	result.ResponseCode = resp.StatusCode
 
-	l.results <- result
+	// external site, the body is only needed for soft 404 detection,
+	// which only applies to html pages
+	external := u.Host != l.Domain
+	if external && (!l.soft404 || !strings.Contains(resp.Header.Get("Content-Type"), "text/html")) {
+		l.pageFetched(page)
+		l.report(result)
+		return
+	}
+
+	var reader io.Reader = resp.Body
+	if external {
+		reader = io.LimitReader(resp.Body, soft404MaxBody)
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		l.pageFetched(page)
+		result = l.errorResult(result, err)
+		l.report(result)
+		return
+	}
+
+	result.Metrics = trace.done(resp, int64(len(body))).withConnection(metrics)
+
+	page.Body = body
+	page.Metrics = result.Metrics
+
+	if len(l.hooks) > 0 {
+		page.Document, err = htmlquery.Parse(bytes.NewReader(body))
+		if err != nil {
+			fmt.Fprintf(l.errorLog, "unable to parse %s, %s", site, err)
+		}
+	}
+	l.pageFetched(page)
+
+	if l.soft404 {
+		problem, ok := l.detectSoft404(u, body)
+		if ok {
+			result.Status = StatusSoft404
+			result.Problem = problem
+			l.report(result)
+			return
+		}
+	}
+
+	l.report(result)
+
+	if external {
+		return
+	}
 
 	// generate of list of links on page
-	links, err := l.ParseBody(resp.Body)
+	links, err := l.parsePageLinks(site, bytes.NewReader(body))
 
 	if err != nil {
 		fmt.Fprintf(l.errorLog, "unable to generate site list, %s", err)
 	}
 
-	for _, link := range links {
+	for _, h := range l.hooks {
+		h.OnLinksExtracted(page, links)
+	}
+
+	for _, found := range links {
+
+		// links are crawled by their rewritten url
+		link := l.Rewrite(found.Url)
+
+		l.addReferrer(link, Referrer{
+			Url:    site,
+			Text:   found.Text,
+			Line:   found.Line,
+			Column: found.Column,
+		})
 
 		if !l.IsCrawled(link) {
 
+			// pages inside crawler traps are reported but not crawled
+			if l.traps != nil && !l.IsExternal(link) {
+				problem, trapped := l.traps.check(link)
+				if trapped {
+					result := Result{
+						Url:           link,
+						ReferringSite: site,
+						Problem:       problem,
+						Status:        StatusTrapped,
+					}
+					if link != found.Url {
+						result.OriginalUrl = found.Url
+					}
+					l.AddSite(link)
+					l.skipped(link, problem)
+					l.report(result)
+					continue
+				}
+			}
+
 			// check if progress bar enabled in LinkChecker struct
 			if l.ProgressBar != nil {
 				l.ProgressBar.Add()
@@ -306,32 +582,83 @@ func (l *LinkChecker) Crawl(site string, referringSite string) {
 			if err != nil {
 				fmt.Fprintln(l.errorLog, err)
 			}
-			go l.Crawl(link, site)
+			go l.Crawl(found.Url, site)
+		}
+	}
+}
+
+// errorResult classifies a request error, separating slow hosts from
+// hosts that are actually down
+func (l *LinkChecker) errorResult(result Result, err error) Result {
+
+	result.ErrorKind = ClassifyError(err)
+	result.Problem = err.Error()
+	result.Status = StatusDown
+
+	if result.ErrorKind == ErrorTimeout {
+		result.Problem = "Client.Timeout exceeded while awaiting headers"
+		result.Status = StatusTimeout
+	}
+
+	return result
+}
+
+// report sends a result to the results channel, storing the outcome
+// of external links in the cache when one is configured and applying
+// any status forced by policy rules
+func (l *LinkChecker) report(result Result) {
+
+	// skipped links were never requested, so there is nothing to reuse
+	if l.cache != nil && !result.Cached && result.Status != StatusSkipped && l.IsExternal(result.Url) {
+		l.cache.Put(result)
+	}
+
+	result.Warnings = append(result.Warnings, l.latencyWarning(result.Metrics)...)
+
+	rule := l.matchPolicy(result.Url)
+	if rule != nil && rule.force != None {
+		result.Status = rule.force
+		if rule.Note != "" {
+			result.Problem = rule.Note
 		}
 	}
+
+	l.countResult(result)
+
+	for _, h := range l.hooks {
+		h.OnResult(result)
+	}
+
+	if l.templates != nil && l.isReportable(result) {
+		l.renderResult(result)
+	}
+
+	l.results <- result
+}
+
+func (l *LinkChecker) IsExternal(link string) bool {
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+
+	return u.Host != l.Domain
 }
 
 func (l *LinkChecker) ParseBody(body io.Reader) ([]string, error) {
 
 	sites := []string{}
-	doc, err := htmlquery.Parse(body)
+
+	links, err := l.ParseLinks(body)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse body, check if a valid io.Reader is being sent, %s", err)
 	}
 
-	list := htmlquery.Find(doc, "//a/@href")
-
-	for _, n := range list {
-		url := htmlquery.InnerText(n)
-
-		if l.IsLinkOkToAdd(url) {
-			url, err = l.CanonicaliseChildUrl(url)
-			if err != nil {
-				fmt.Fprintf(l.errorLog, "unable to canonicalise url: %s, %s", url, err)
-			}
-			sites = append(sites, url)
-		}
+	for _, link := range links {
+		sites = append(sites, link.Url)
 	}
+
 	return sites, nil
 }
 
@@ -348,39 +675,53 @@ func (l *LinkChecker) IsLinkOkToAdd(link string) bool {
 
 func (l *LinkChecker) HeadStatus(link string) (int, error) {
 
-	request, err := http.NewRequest(http.MethodHead, link, nil)
+	resp, _, err := l.head(link)
+
+	return resp.StatusCode, err
+}
+
+func (l *LinkChecker) GetResponse(link string) (*http.Response, error) {
+
+	resp, _, err := l.get(link)
+
+	return resp, err
+}
+
+// head makes a head request, returning the response with its body closed
+func (l *LinkChecker) head(link string) (*http.Response, Metrics, error) {
+
+	request, err := l.newRequest(http.MethodHead, link, nil)
 	if err != nil {
-		fmt.Fprintln(l.errorLog, err)
+		return &http.Response{}, Metrics{}, err
 	}
 
-	request.Header.Set("user-agent", "linkchecker")
-	request.Header.Set("accept", "*/*")
+	request, trace := newRequestTrace(request)
 
-	resp, err := l.HTTPClient.Do(request)
+	resp, err := l.do(request)
 	if err != nil {
-		return 0, err
+		return &http.Response{}, trace.done(nil, 0), err
 	}
-	defer resp.Body.Close()
+	resp.Body.Close()
 
-	return resp.StatusCode, nil
+	return resp, trace.done(resp, resp.ContentLength), nil
 }
 
-func (l *LinkChecker) GetResponse(link string) (*http.Response, error) {
+func (l *LinkChecker) get(link string) (*http.Response, *requestTrace, error) {
 
-	request, err := http.NewRequest(http.MethodGet, link, nil)
+	request, err := l.newRequest(http.MethodGet, link, nil)
 	if err != nil {
-		fmt.Fprintln(l.errorLog, err)
+		return &http.Response{}, &requestTrace{start: time.Now()}, err
 	}
-	request.Header.Set("user-agent", "linkchecker")
-	request.Header.Set("accept", "*/*")
 
-	resp, err := l.HTTPClient.Do(request)
+	request, trace := newRequestTrace(request)
+
+	resp, err := l.do(request)
 
 	if err != nil {
-		return &http.Response{}, err
+		return &http.Response{}, trace, err
 	}
 
-	return resp, nil
+	return resp, trace, nil
 }
 
 type CheckLink struct {
@@ -438,10 +779,18 @@ func (l *LinkChecker) CanonicaliseUrl(site string) string {
 }
 
 func (l *LinkChecker) CanonicaliseChildUrl(site string) (string, error) {
+	return l.canonicaliseChildUrl(site, "")
+}
+
+func (l *LinkChecker) canonicaliseChildUrl(site string, pageUrl string) (string, error) {
 
 	canonical := strings.TrimSpace(site)
 	var err error
 
+	if l.Scheme == "file" {
+		return l.canonicaliseFileUrl(canonical, pageUrl)
+	}
+
 	if canonical == "./" {
 		// set to empty string and just use domain name
 		canonical = l.Scheme + "://" + l.Domain
@@ -475,21 +824,28 @@ func (l *LinkChecker) elapsed(what string) func() {
 
 // Result is a struct that contains the status code and url of a link
 type Result struct {
-	ResponseCode  int
-	Url           string
-	Problem       string
-	ReferringSite string
-	Status        Status
+	ResponseCode  int        `json:"response_code"`
+	Url           string     `json:"url"`
+	OriginalUrl   string     `json:"original_url,omitempty"`
+	Problem       string     `json:"problem"`
+	ReferringSite string     `json:"referring_site"`
+	Status        Status     `json:"status"`
+	ErrorKind     ErrorKind  `json:"error_kind,omitempty"`
+	Cached        bool       `json:"cached,omitempty"`
+	Warnings      []string   `json:"warnings,omitempty"`
+	Metrics       Metrics    `json:"metrics"`
+	Referrers     []Referrer `json:"referrers,omitempty"`
+	Retries       int        `json:"retries,omitempty"`
+	FinalUrl      string     `json:"final_url,omitempty"`
 }
 
 func CheckSiteLinks(site string, opts ...Option) <-chan Result {
-	l, err := NewLinkChecker()
-	for _, o := range opts {
-		o(l)
-	}
-
+	l, err := NewLinkChecker(opts...)
 	if err != nil {
-		fmt.Fprintf(l.errorLog, "unable to create linkchecker struct, %s", err)
+		fmt.Fprintf(os.Stderr, "unable to create linkchecker struct, %s", err)
+		results := make(chan Result)
+		close(results)
+		return results
 	}
 
 	err = l.Check(site)
@@ -518,18 +874,67 @@ var StatusStringMap = map[Status]string{
 	StatusDown:        "Down",
 	StatusRateLimited: "RateLimited",
 	Status999:         "Unable to verify",
+	StatusTimeout:     "Timeout",
+	StatusSkipped:     "Skipped",
+	StatusSoft404:     "Soft 404",
+	StatusTrapped:     "Trapped",
 }
 
 func (s Status) String() string {
 	return StatusStringMap[s]
 }
 
+// MarshalText writes the status by name so that reports stay readable
+// if statuses are added
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Status) UnmarshalText(text []byte) error {
+
+	if string(text) == StatusStringMap[None] {
+		*s = None
+		return nil
+	}
+
+	status, err := ParseStatus(string(text))
+	if err != nil {
+		return err
+	}
+
+	*s = status
+	return nil
+}
+
+// UnmarshalJSON also accepts the numeric statuses written by earlier
+// versions, such as in result caches
+func (s *Status) UnmarshalJSON(data []byte) error {
+
+	number, err := strconv.Atoi(string(data))
+	if err == nil {
+		*s = Status(number)
+		return nil
+	}
+
+	var text string
+	err = json.Unmarshal(data, &text)
+	if err != nil {
+		return err
+	}
+
+	return s.UnmarshalText([]byte(text))
+}
+
 const (
 	None Status = iota
 	StatusUp
 	StatusDown
 	StatusRateLimited
 	Status999
+	StatusTimeout
+	StatusSkipped
+	StatusSoft404
+	StatusTrapped
 )
 
 var HttpStatusMap = map[int]Status{
@@ -554,6 +959,10 @@ var StatusColorMap = map[Status]Color{
 	StatusDown:        ColorRed,
 	StatusRateLimited: ColorYellow,
 	Status999:         ColorYellow,
+	StatusTimeout:     ColorYellow,
+	StatusSkipped:     ColorReset,
+	StatusSoft404:     ColorRed,
+	StatusTrapped:     ColorYellow,
 }
 
 func (r Result) String() string {
@@ -570,7 +979,44 @@ func (r Result) String() string {
 
 	color := StatusColorMap[status]
 
-	str := []string{string(color), "URL: ", r.Url, " \nStatus: ", r.Status.String(), "\nStatus Code: ", strconv.Itoa(r.ResponseCode), " \nProblem: ", r.Problem, "\nReferring URL: ", r.ReferringSite, "\n", string(ColorReset)}
+	errorKind := ""
+	if r.ErrorKind != ErrorNone {
+		errorKind = " (" + r.ErrorKind.String() + ")"
+	}
+
+	cached := ""
+	if r.Cached {
+		cached = " (cached)"
+	}
+
+	original := ""
+	if r.OriginalUrl != "" {
+		original = " (rewritten from " + r.OriginalUrl + ")"
+	}
+
+	str := []string{string(color), "URL: ", r.Url, original, " \nStatus: ", r.Status.String(), cached, "\nStatus Code: ", strconv.Itoa(r.ResponseCode), " \nProblem: ", r.Problem, errorKind, "\nReferring URL: ", r.ReferringSite, "\n"}
+
+	for _, referrer := range r.Referrers {
+		str = append(str, "Linked From: ", referrer.Url, " (line ", strconv.Itoa(referrer.Line), ", column ", strconv.Itoa(referrer.Column), ") ", strconv.Quote(referrer.Text), "\n")
+	}
+
+	if r.FinalUrl != "" {
+		str = append(str, "Redirected To: ", r.FinalUrl, "\n")
+	}
+
+	if r.Metrics.Total >= time.Millisecond {
+		str = append(str, "Response Time: ", r.Metrics.Total.Round(time.Millisecond).String(), "\n")
+	}
+
+	if r.Retries > 0 {
+		str = append(str, "Retries: ", strconv.Itoa(r.Retries), "\n")
+	}
+
+	for _, warning := range r.Warnings {
+		str = append(str, "Warning: ", warning, "\n")
+	}
+
+	str = append(str, string(ColorReset))
 
 	return strings.Join(str, "")
 }
@@ -580,21 +1026,104 @@ func RunCLI() {
 
 	flagSet := flag.NewFlagSet("flags", flag.ExitOnError)
 	slow := flagSet.Bool("slow", false, "linkchecker rate set to 1 request per second")
+	dir := flagSet.String("dir", "", "check a local build directory without a web server")
+	cleanURLs := flagSet.Bool("clean-urls", false, "with serve-and-check, serve /about from about.html")
+	notFoundPage := flagSet.String("not-found-page", "", "with serve-and-check, file served with a 404 status for missing pages")
+	interval := flagSet.Duration("interval", time.Second, "with watch, how often the directory is checked for changes")
 	normal := flagSet.Bool("normal", false, "linkchecker rate set two 2 requests per second")
 	fast := flagSet.Bool("fast", false, "linkchecker rate set to 10 requests per second")
 	furious := flagSet.Bool("furious", false, "linkchecker rate set to 20 requests per second")
 	warp := flagSet.Bool("warp", false, "linkchecker rate set to 100 requests per second")
+	cachePath := flagSet.String("cache", "", "file used to cache external link results between runs")
+	cacheTTL := flagSet.Duration("cache-ttl", 24*time.Hour, "how long successful external link results are cached")
+	cacheFailureTTL := flagSet.Duration("cache-failure-ttl", time.Hour, "how long failed external link results are cached")
+	insecure := flagSet.Bool("insecure", false, "check links on hosts with invalid certificates, flagging the certificates as warnings")
+	certExpiry := flagSet.Duration("cert-expiry", 30*24*time.Hour, "warn about certificates expiring within this window")
+	var headers, cookies, authHosts stringsFlag
+	flagSet.Var(&headers, "header", "extra request header as \"Key: Value\", may be repeated")
+	flagSet.Var(&cookies, "cookie", "cookie sent to the site as name=value, may be repeated")
+	basicAuth := flagSet.String("basic-auth", "", "basic auth credentials as user:password")
+	bearerToken := flagSet.String("bearer-token", "", "bearer token sent in the authorization header")
+	flagSet.Var(&authHosts, "auth-host", "host that credentials and extra headers are sent to, may be repeated.  defaults to the site being checked")
+	loginConfig := flagSet.String("login", "", "json file describing a login form submitted before crawling")
+	rulesFile := flagSet.String("rules", "", "json file of rules that skip links, accept status codes or force a status")
+	soft404 := flagSet.Bool("soft404", false, "report pages that return 200 but look like not found pages")
+	latencyThreshold := flagSet.Duration("latency-threshold", 0, "warn about links slower than this duration")
+	slowest := flagSet.Int("slowest", 0, "list this many of the slowest links after checking")
+	traps := flagSet.Bool("traps", false, "report and stop crawling urls that look like crawler traps, such as endless calendars")
+	var trapCaps stringsFlag
+	flagSet.Var(&trapCaps, "trap-cap", "crawl at most n urls matching a regular expression, as pattern=n, may be repeated")
+	format := flagSet.String("format", "text", "output format, one of text, json, jsonl, csv, tsv, junit, sarif, html, markdown, github or gitlab")
+	resultTemplate := flagSet.String("template", "", "text/template for each result, or @file to read it from a file")
+	headerTemplate := flagSet.String("header-template", "", "text/template written before the results, or @file")
+	footerTemplate := flagSet.String("footer-template", "", "text/template written after the results with the summary, or @file")
+	maxSize := flagSet.Int("max-size", DefaultMarkdownSize, "largest markdown summary written, in bytes")
+	baselinePath := flagSet.String("baseline", "", "json results of a previous run, new broken links are listed first in the markdown summary")
+	var sourceMappings stringsFlag
+	flagSet.Var(&sourceMappings, "source-map", "map pages to the files they are built from, as prefix=source where {path} in source is replaced by the rest of the url path, may be repeated")
+	var junitFailOn stringsFlag
+	flagSet.Var(&junitFailOn, "junit-fail-on", "status that fails a junit test case as well as Down, Timeout and Soft 404, such as RateLimited or 999, may be repeated")
+	retries := flagSet.Int("retries", 0, "request links again this many times when they fail in a way that may be temporary")
+	retryDelay := flagSet.Duration("retry-delay", time.Second, "wait between retries, multiplied by the attempt number")
+	columns := flagSet.String("columns", "", "comma separated columns written by the csv and tsv formats")
+	group := flagSet.String("group", "", "after checking, list results grouped by \"target\" link or by referring \"page\"")
+	var resolves stringsFlag
+	flagSet.Var(&resolves, "resolve", "connect to address for host:port, as host:port=address, may be repeated")
+	var rewriteRules []RewriteRule
+	flagSet.Var(&rewriteFlag{rules: &rewriteRules}, "rewrite", "rewrite links starting with a prefix before fetching, as prefix=replacement, may be repeated")
+	flagSet.Var(&rewriteFlag{rules: &rewriteRules, isRegex: true}, "rewrite-regex", "rewrite links matching a regular expression before fetching, as pattern=replacement, may be repeated")
 
 	if len(os.Args) < 2 {
 		help(os.Args[0])
 		os.Exit(1)
 	}
 
-	flagSet.Parse(os.Args[2:])
+	// the site comes first, unless checking a directory with -dir,
+	// serve-and-check or watch
+	args := os.Args[1:]
+	site, serveDir, watchDir := "", "", ""
+	if args[0] == "serve-and-check" && len(args) > 1 {
+		serveDir, args = args[1], args[2:]
+	} else if args[0] == "watch" && len(args) > 1 {
+		watchDir, args = args[1], args[2:]
+		*dir = watchDir
+	} else if !strings.HasPrefix(args[0], "-") {
+		site, args = args[0], args[1:]
+	}
+
+	flagSet.Parse(args)
+
+	if serveDir != "" {
+		staticOpts := []OptionStatic{}
+		if *cleanURLs {
+			staticOpts = append(staticOpts, WithCleanURLs())
+		}
+		if *notFoundPage != "" {
+			staticOpts = append(staticOpts, WithNotFoundPage(*notFoundPage))
+		}
+
+		server, err := NewStaticServer(serveDir, staticOpts...)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer server.Close()
+
+		site = server.URL
+	}
 
-	var speed CheckSpeed
+	if *dir != "" {
+		fileUrl, err := FileUrl(*dir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		site = fileUrl
+	}
 
-	if *normal || len(os.Args) < 3 {
+	speed := CheckSpeedNormal
+
+	if *normal {
 		speed = CheckSpeedNormal
 	} else if *slow {
 		speed = CheckSpeedSlow
@@ -608,35 +1137,248 @@ func RunCLI() {
 
 	_, ok := CheckSpeedMap[speed]
 
-	site := os.Args[1]
-	if site == "help" || !ok {
+	if site == "" || site == "help" || !ok {
 		fmt.Println(os.Args[0])
 		help(os.Args[0])
 		os.Exit(0)
 	}
 
-	l, err := NewLinkChecker(
-		WithProgressBar(),
+	opts := []Option{
 		WithLinkcheckerSpeed(speed),
 		WithErrorLog(io.Discard),
-	)
+		WithCertificateExpiryWarning(*certExpiry),
+	}
+
+	if *insecure {
+		opts = append(opts, WithInsecure())
+	}
+
+	if *cachePath != "" {
+		opts = append(opts, WithResultCache(*cachePath, *cacheTTL, *cacheFailureTTL))
+	}
+
+	for _, header := range headers {
+		parts := strings.SplitN(header, ":", 2)
+		if len(parts) != 2 {
+			fmt.Fprintf(os.Stderr, "invalid header %q, expected \"Key: Value\"\n", header)
+			os.Exit(1)
+		}
+		opts = append(opts, WithHeader(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), authHosts...))
+	}
+
+	if len(cookies) > 0 {
+		jar, err := cookieJarFor(site, cookies)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		opts = append(opts, WithCookieJar(jar))
+	}
+
+	if *basicAuth != "" {
+		parts := strings.SplitN(*basicAuth, ":", 2)
+		parts = append(parts, "")
+		opts = append(opts, WithBasicAuth(parts[0], parts[1], authHosts...))
+	}
+
+	if *bearerToken != "" {
+		opts = append(opts, WithBearerToken(*bearerToken, authHosts...))
+	}
+
+	for _, resolve := range resolves {
+		hostPort, address, err := ParseResolve(resolve)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		opts = append(opts, WithResolve(hostPort, address))
+	}
+
+	if len(rewriteRules) > 0 {
+		opts = append(opts, WithRewriteRules(rewriteRules...))
+	}
+
+	if *soft404 {
+		opts = append(opts, WithSoft404Detection())
+	}
+
+	if *retries > 0 {
+		opts = append(opts, WithRetries(*retries, *retryDelay))
+	}
+
+	if *latencyThreshold > 0 {
+		opts = append(opts, WithLatencyThreshold(*latencyThreshold))
+	}
+
+	if *traps || len(trapCaps) > 0 {
+		config := DefaultTrapConfig
+		config.PatternCaps = make(map[string]int)
+		for _, trapCap := range trapCaps {
+			i := strings.LastIndex(trapCap, "=")
+			max, err := strconv.Atoi(trapCap[i+1:])
+			if i < 0 || err != nil {
+				fmt.Fprintf(os.Stderr, "invalid trap cap %q, expected pattern=n\n", trapCap)
+				os.Exit(1)
+			}
+			config.PatternCaps[trapCap[:i]] = max
+		}
+		opts = append(opts, WithTrapDetection(config))
+	}
+
+	if *rulesFile != "" {
+		opts = append(opts, WithPolicyFile(*rulesFile))
+	}
+
+	if *loginConfig != "" {
+		login, err := LoadLogin(*loginConfig)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		opts = append(opts, WithLogin(login))
+	}
+
+	if watchDir != "" {
+		w, err := NewWatcher(watchDir, opts...)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		err = w.Run(ctx, *interval)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	sources := SourceMap{}
+	for _, value := range sourceMappings {
+		mapping, err := ParseSourceMapping(value)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		sources = append(sources, mapping)
+	}
+
+	failOn := []Status{}
+	for _, name := range junitFailOn {
+		status, err := ParseStatus(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		failOn = append(failOn, status)
+	}
+
+	templates := Templates{}
+	for _, t := range []struct {
+		value string
+		text  *string
+	}{
+		{*resultTemplate, &templates.Result},
+		{*headerTemplate, &templates.Header},
+		{*footerTemplate, &templates.Footer},
+	} {
+		*t.text = t.value
+		if strings.HasPrefix(t.value, "@") {
+			data, err := os.ReadFile(t.value[1:])
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			*t.text = string(data)
+		}
+	}
+
+	useTemplate := templates != Templates{}
+	if useTemplate {
+		opts = append(opts, WithTemplate(templates))
+	}
+
+	var reporter Reporter
+	var err error
+
+	switch {
+	case *format == "text":
+	case *format == "csv" && *columns != "":
+		reporter, err = NewCSVReporter(os.Stdout, strings.Split(*columns, ",")...)
+	case *format == "tsv" && *columns != "":
+		reporter, err = NewTSVReporter(os.Stdout, strings.Split(*columns, ",")...)
+	case *format == "junit":
+		reporter = NewJUnitReporter(os.Stdout, failOn...)
+	case *format == "sarif":
+		reporter = NewSARIFReporter(os.Stdout, sources)
+	case *format == "github":
+		reporter = NewGitHubReporter(os.Stdout, sources)
+	case *format == "gitlab":
+		reporter = NewGitLabReporter(os.Stdout, sources)
+	case *format == "markdown":
+		var baseline *Report
+		if *baselinePath != "" {
+			report, loadErr := LoadReport(*baselinePath)
+			if loadErr != nil {
+				fmt.Fprintln(os.Stderr, loadErr)
+				os.Exit(1)
+			}
+			baseline = &report
+		}
+		reporter = NewMarkdownReporter(os.Stdout, *maxSize, baseline)
+	default:
+		reporter, err = NewReporter(*format, os.Stdout)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// every checked link is a junit test case, and any link may be
+	// redirected or slow in the html report
+	if *format == "junit" || *format == "html" {
+		opts = append(opts, WithVerboseMode())
+	}
+
+	// machine readable formats and templates need the output to themselves
+	if reporter == nil && !useTemplate {
+		opts = append(opts, WithProgressBar())
+	}
+
+	l, err := NewLinkChecker(opts...)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
-	l.ProgressBar.ctx, l.ProgressBar.cancel = context.WithCancel(context.Background())
+	if l.ProgressBar != nil {
+		l.ProgressBar.ctx, l.ProgressBar.cancel = context.WithCancel(context.Background())
+
+		defer l.elapsed("linkchecker")()
 
-	defer l.elapsed("linkchecker")()
+		go l.ProgressBar.Refresher()
+	}
 
-	go l.ProgressBar.Refresher()
+	all := []Result{}
+	done := make(chan struct{})
 
 	go func() {
+		defer close(done)
 		for result := range l.StreamResults() {
-			if !l.verboseMode {
-				if result.Status != StatusUp {
-					fmt.Fprintln(l.output, result)
+			all = append(all, result)
+			if !l.isReportable(result) {
+				continue
+			}
+			if reporter != nil {
+				err := reporter.Result(result)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
 				}
-			} else {
+			} else if *group == "" && !useTemplate {
 				fmt.Fprintln(l.output, result)
 			}
 		}
@@ -644,11 +1386,95 @@ func RunCLI() {
 
 	err = l.Check(site)
 	if err != nil {
-		fmt.Fprintln(l.errorLog, err)
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	<-done
+
+	if l.ProgressBar != nil {
+		//close(l.ProgressBar.done)
+		l.ProgressBar.cancel()
 	}
 
-	//close(l.ProgressBar.done)
-	l.ProgressBar.cancel()
+	results := []Result{}
+	for _, result := range all {
+		if l.isReportable(result) {
+			result.Referrers = l.Referrers(result.Url)
+			results = append(results, result)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Url < results[j].Url })
+
+	if reporter != nil {
+		err = reporter.Finish(l.Report(results))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *group != "" {
+		printGrouped(l.output, results, *group)
+	}
+
+	if *slowest > 0 {
+		fmt.Fprintf(l.output, "\nSlowest %d links:\n", *slowest)
+		for _, result := range SlowestResults(all, *slowest) {
+			fmt.Fprintf(l.output, "%v\t%s\n", result.Metrics.Total.Round(time.Millisecond), result.Url)
+		}
+	}
+}
+
+func printGrouped(w io.Writer, results []Result, group string) {
+
+	if group != "page" {
+		for _, result := range results {
+			fmt.Fprintln(w, result)
+		}
+		return
+	}
+
+	for _, page := range GroupByReferrer(results) {
+		fmt.Fprintf(w, "Page: %s\n", page.Page)
+		for _, link := range page.Links {
+			color := StatusColorMap[link.Result.Status]
+			fmt.Fprintf(w, "%s  line %d, column %d: %s %s %q%s\n", color, link.Referrer.Line, link.Referrer.Column, link.Result.Status, link.Result.Url, link.Referrer.Text, ColorReset)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// stringsFlag collects the values of a flag that may be repeated
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// rewriteFlag collects rewrite rules in the order they are given, so
+// prefix and regex rules can be mixed
+type rewriteFlag struct {
+	rules   *[]RewriteRule
+	isRegex bool
+}
+
+func (r *rewriteFlag) String() string {
+	return ""
+}
+
+func (r *rewriteFlag) Set(value string) error {
+	rule, err := ParseRewriteRule(value, r.isRegex)
+	if err != nil {
+		return err
+	}
+	*r.rules = append(*r.rules, rule)
+	return nil
 }
 
 func help(cliArg string) {
@@ -672,10 +1498,50 @@ func help(cliArg string) {
 	  -fast: sets the linkchecker rate set to 10 requests per second.
 	  -furious: sets the linkchecker rate set to 20 requests per second.
 	  -warp: sets the linkchecker rate set to 100 requests per second.
+	  -dir: check the html files in a local build directory without a web server.  the site argument can be left out.
+	  -clean-urls: with serve-and-check, serve /about from about.html.
+	  -not-found-page: with serve-and-check, file in the directory served with a 404 status for missing pages, such as 404.html.
+	  -format: output format.  text (default), json for one document with the results, summary and crawl metadata, jsonl for one result per line as they arrive, csv for one row per link and referring page, tsv for the same with tab separated values, junit for a test suite per referring page with a test case per link, sarif for code scanning alerts, html for a single page report, markdown for a summary to post as a pull request comment, github for GitHub Actions annotations, or gitlab for a GitLab code quality report.
+	  -template: text/template rendered for each result in place of the default layout, with every Result field, such as '{{.Status}} {{.Url}}'.  @file reads the template from a file.
+	  -header-template: text/template written before the results, given the report metadata and summary.  @file reads the template from a file.
+	  -footer-template: text/template written after the results, given the report metadata, summary and results.  @file reads the template from a file.
+	  -max-size: largest markdown summary written, in bytes.  defaults to 60000.
+	  -baseline: json results of a previous run, written with -format json.  broken links that are new since are listed first in the markdown summary.
+	  -source-map: used by the sarif, github and gitlab formats to map pages to the files they are built from, as prefix=source.  {path} in source is replaced by the rest of the url path, so /docs/=content/docs/{path}.md maps /docs/setup to content/docs/setup.md.  may be repeated.
+	  -junit-fail-on: status that fails a junit test case as well as Down, Timeout and Soft 404, such as RateLimited or 999.  may be repeated.
+	  -retries: request links again this many times when they time out, the connection is refused or reset, or they return 429 or 5xx.
+	  -retry-delay: wait between retries, multiplied by the attempt number.  defaults to 1s.
+	  -columns: comma separated columns written by the csv and tsv formats, from url, original_url, referrer, line, column, text, status, code, problem, error_kind, cached, time_ms, content_type and warnings.
+	  -interval: with watch, how often the directory is checked for changes.  defaults to 1s.
+	  -cache: file used to cache external link results between runs.
+	  -cache-ttl: how long successful external link results are cached.  defaults to 24h.
+	  -cache-failure-ttl: how long failed external link results are cached.  defaults to 1h.
+	  -insecure: check links on hosts with invalid certificates, such as self-signed staging hosts.  invalid certificates are still reported as warnings.
+	  -cert-expiry: warn about certificates expiring within this window.  defaults to 720h.
+	  -header: extra request header as "Key: Value", sent to the same hosts as credentials.  may be repeated.
+	  -cookie: cookie sent to the site as name=value.  may be repeated.
+	  -basic-auth: basic auth credentials as user:password.
+	  -bearer-token: bearer token sent in the authorization header.
+	  -auth-host: host that credentials and extra headers are sent to.  may be repeated.  defaults to the site being checked.
+	  -login: json file describing a login form submitted before crawling.
+	  -rules: json file of rules that skip links, accept status codes or force a status for urls matching a pattern.
+	  -soft404: report pages that return 200 but look like not found pages.
+	  -latency-threshold: warn about links slower than this duration, such as 2s.
+	  -slowest: list this many of the slowest links after checking.
+	  -traps: report and stop crawling urls that look like crawler traps, such as endless calendars and faceted search pages.
+	  -trap-cap: crawl at most n urls matching a regular expression, as pattern=n.  may be repeated.  enables -traps.
+	  -group: after checking, list results with every page linking to them with "target", or list each referring page with its links with "page".
+	  -resolve: connect to address for host:port, as host:port=address.  may be repeated.
+	  -rewrite: rewrite links starting with a prefix before fetching, as prefix=replacement.  may be repeated.
+	  -rewrite-regex: rewrite links matching a regular expression before fetching, as pattern=replacement.  may be repeated.
 
 	Usage:
 	%s https://somewebpage123.com
-	`, arg)
+	%s file:///path/to/public
+	%s -dir ./public
+	%s serve-and-check ./public -clean-urls -not-found-page 404.html
+	%s watch ./public
+	`, arg, arg, arg, arg, arg)
 }
 
 type CheckSpeed int
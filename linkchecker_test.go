@@ -14,6 +14,12 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
+// response timings vary between runs
+var ignoreMetrics = cmpopts.IgnoreFields(linkchecker.Result{}, "Metrics")
+
+// for tests that are not about where links were found
+var ignoreReferrers = cmpopts.IgnoreFields(linkchecker.Result{}, "Referrers")
+
 func TestCheckVerbose(t *testing.T) {
 	t.Parallel()
 
@@ -40,18 +46,30 @@ func TestCheckVerbose(t *testing.T) {
 			Url:           ts.URL,
 			ReferringSite: ts.URL,
 			Status:        linkchecker.StatusUp,
+			Referrers: []linkchecker.Referrer{
+				{Url: ts.URL + "/about", Text: "a link to home.html", Line: 9, Column: 20},
+				{Url: ts.URL + "/home", Text: "a link to about.html", Line: 10, Column: 20},
+			},
 		},
 		{
 			ResponseCode:  http.StatusOK,
 			Url:           ts.URL + "/about",
 			ReferringSite: ts.URL,
 			Status:        linkchecker.StatusUp,
+			FinalUrl:      ts.URL + "/about/",
+			Referrers: []linkchecker.Referrer{
+				{Url: ts.URL, Text: "a link to about.html", Line: 9, Column: 20},
+			},
 		},
 		{
 			ResponseCode:  http.StatusOK,
 			Url:           ts.URL + "/home",
 			ReferringSite: ts.URL,
 			Status:        linkchecker.StatusUp,
+			FinalUrl:      ts.URL + "/home/",
+			Referrers: []linkchecker.Referrer{
+				{Url: ts.URL, Text: "a link to home.html", Line: 10, Column: 20},
+			},
 		},
 		{
 			ResponseCode:  http.StatusNotFound,
@@ -59,15 +77,18 @@ func TestCheckVerbose(t *testing.T) {
 			ReferringSite: ts.URL + "/about",
 			Status:        linkchecker.StatusDown,
 			Problem:       "Non OK response",
+			Referrers: []linkchecker.Referrer{
+				{Url: ts.URL + "/about", Text: "a link to home.html", Line: 10, Column: 20},
+			},
 		},
 	}
 
 	got := l.GetAllResults()
 
-	if !cmp.Equal(want, got, cmpopts.SortSlices(func(x, y linkchecker.Result) bool {
+	if !cmp.Equal(want, got, ignoreMetrics, cmpopts.SortSlices(func(x, y linkchecker.Result) bool {
 		return x.Url < y.Url
 	})) {
-		t.Fatal(cmp.Diff(want, got))
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics))
 	}
 
 }
@@ -103,12 +124,14 @@ func TestStreamResults(t *testing.T) {
 			Url:           ts.URL + "/about",
 			ReferringSite: ts.URL,
 			Status:        linkchecker.StatusUp,
+			FinalUrl:      ts.URL + "/about/",
 		},
 		{
 			ResponseCode:  http.StatusOK,
 			Url:           ts.URL + "/home",
 			ReferringSite: ts.URL,
 			Status:        linkchecker.StatusUp,
+			FinalUrl:      ts.URL + "/home/",
 		},
 		{
 			ResponseCode:  http.StatusNotFound,
@@ -130,10 +153,10 @@ func TestStreamResults(t *testing.T) {
 		got = append(got, result)
 	}
 
-	if !cmp.Equal(want, got, cmpopts.SortSlices(func(x, y linkchecker.Result) bool {
+	if !cmp.Equal(want, got, ignoreMetrics, cmpopts.SortSlices(func(x, y linkchecker.Result) bool {
 		return x.Url < y.Url
 	})) {
-		t.Fatal(cmp.Diff(want, got))
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics))
 	}
 
 }
@@ -164,15 +187,18 @@ func TestCheck(t *testing.T) {
 			ReferringSite: ts.URL + "/about",
 			Status:        linkchecker.StatusDown,
 			Problem:       "Non OK response",
+			Referrers: []linkchecker.Referrer{
+				{Url: ts.URL + "/about", Text: "a link to home.html", Line: 10, Column: 20},
+			},
 		},
 	}
 
 	got := l.GetAllResults()
 
-	if !cmp.Equal(want, got, cmpopts.SortSlices(func(x, y linkchecker.Result) bool {
+	if !cmp.Equal(want, got, ignoreMetrics, cmpopts.SortSlices(func(x, y linkchecker.Result) bool {
 		return x.Url < y.Url
 	})) {
-		t.Fatal(cmp.Diff(want, got))
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics))
 	}
 
 }
@@ -339,7 +365,7 @@ func TestErrorHandling(t *testing.T) {
 	want := linkchecker.Result{
 		Url:           "https://boguswebsite/home",
 		Status:        linkchecker.StatusDown,
-		Problem:       `Head "https://boguswebsite/home": dial tcp: lookup boguswebsite: no such host`,
+		ErrorKind:     linkchecker.ErrorDNSNotFound,
 		ReferringSite: "https://boguswebsite/home",
 	}
 
@@ -350,8 +376,39 @@ func TestErrorHandling(t *testing.T) {
 
 	got := <-l.StreamResults()
 
-	if !cmp.Equal(want, got) {
-		t.Fatal(cmp.Diff(want, got))
+	// the problem text depends on the resolver in use
+	if !cmp.Equal(want, got, ignoreMetrics, cmpopts.IgnoreFields(linkchecker.Result{}, "Problem")) {
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics))
+	}
+
+}
+
+func TestCheckInvalidSiteClosesResults(t *testing.T) {
+	t.Parallel()
+
+	l, err := linkchecker.NewLinkChecker()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = l.Check("://boguswebsite")
+	if err == nil {
+		t.Fatal("want error for invalid site")
+	}
+
+	// results are closed, so reading them doesn't block
+	done := make(chan []linkchecker.Result, 1)
+	go func() {
+		done <- l.GetAllResults()
+	}()
+
+	select {
+	case got := <-done:
+		if len(got) != 0 {
+			t.Fatalf("want no results, got: %v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("want results closed after Check fails")
 	}
 
 }
@@ -426,15 +483,39 @@ func TestTimeoutHeadRequest(t *testing.T) {
 			ResponseCode:  0,
 			Url:           ts.URL,
 			ReferringSite: ts.URL,
-			Status:        linkchecker.StatusRateLimited,
+			Status:        linkchecker.StatusTimeout,
+			ErrorKind:     linkchecker.ErrorTimeout,
 			Problem:       "Client.Timeout exceeded while awaiting headers",
 		},
 	}
 
 	got := l.GetAllResults()
 
-	if !cmp.Equal(want, got) {
-		t.Fatal(cmp.Diff(want, got))
+	if !cmp.Equal(want, got, ignoreMetrics) {
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics))
+	}
+
+}
+
+func TestCheckSiteLinksInvalidOption(t *testing.T) {
+	t.Parallel()
+
+	requests := 0
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer ts.Close()
+
+	results := linkchecker.CheckSiteLinks(ts.URL, linkchecker.WithTrapDetection(linkchecker.TrapConfig{
+		PatternCaps: map[string]int{"(": 1},
+	}))
+
+	for result := range results {
+		t.Fatalf("want no results, got: %+v", result)
+	}
+
+	if requests != 0 {
+		t.Fatalf("want site not checked, got %d requests", requests)
 	}
 
 }
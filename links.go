@@ -0,0 +1,212 @@
+package linkchecker
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Link is an anchor found on a page and its position in the page source
+type Link struct {
+	Url    string `json:"url"`
+	Text   string `json:"text"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// Referrer is a page that links to a url, with the anchor text and the
+// position of the link in the page source
+type Referrer struct {
+	Url    string `json:"url"`
+	Text   string `json:"text"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// ParseLinks returns the links on a page that should be checked, with
+// their anchor text and line and column in the page source.  An error
+// reading the page is returned with the links found before it.
+func (l *LinkChecker) ParseLinks(body io.Reader) ([]Link, error) {
+	return l.parsePageLinks("", body)
+}
+
+// parsePageLinks is ParseLinks for the page at pageUrl, which relative
+// links in local build directories are resolved against
+func (l *LinkChecker) parsePageLinks(pageUrl string, body io.Reader) ([]Link, error) {
+
+	links := []Link{}
+
+	found, err := extractLinks(body)
+
+	for _, link := range found {
+		if !l.IsLinkOkToAdd(link.Url) {
+			continue
+		}
+
+		url, err := l.canonicaliseChildUrl(link.Url, pageUrl)
+		if err != nil {
+			fmt.Fprintf(l.errorLog, "unable to canonicalise url: %s, %s", link.Url, err)
+		}
+
+		link.Url = url
+		links = append(links, link)
+	}
+
+	return links, err
+}
+
+// extractLinks tokenizes html, tracking the line and column of each
+// anchor and collecting the text inside it
+func extractLinks(body io.Reader) ([]Link, error) {
+
+	links := []Link{}
+
+	var current *Link
+	var text strings.Builder
+
+	finish := func() {
+		if current != nil {
+			current.Text = strings.Join(strings.Fields(text.String()), " ")
+			links = append(links, *current)
+			current = nil
+		}
+		text.Reset()
+	}
+
+	z := html.NewTokenizer(body)
+	line, column := 1, 1
+
+	for {
+		tokenType := z.Next()
+		if tokenType == html.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				finish()
+				return links, err
+			}
+			break
+		}
+
+		tokenLine, tokenColumn := line, column
+
+		raw := z.Raw()
+		if i := bytes.LastIndexByte(raw, '\n'); i >= 0 {
+			line += bytes.Count(raw, []byte("\n"))
+			column = len(raw) - i
+		} else {
+			column += len(raw)
+		}
+
+		switch tokenType {
+		case html.StartTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) != "a" {
+				continue
+			}
+			finish()
+			for hasAttr {
+				var key, value []byte
+				key, value, hasAttr = z.TagAttr()
+				if string(key) == "href" {
+					current = &Link{Url: string(value), Line: tokenLine, Column: tokenColumn}
+				}
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if string(name) == "a" {
+				finish()
+			}
+		case html.TextToken:
+			if current != nil {
+				text.Write(z.Text())
+			}
+		}
+	}
+
+	finish()
+
+	return links, nil
+}
+
+func (l *LinkChecker) addReferrer(target string, referrer Referrer) {
+
+	l.referrerMutex.Lock()
+	defer l.referrerMutex.Unlock()
+
+	l.referrers[target] = append(l.referrers[target], referrer)
+}
+
+// Referrers returns every page found linking to target
+func (l *LinkChecker) Referrers(target string) []Referrer {
+
+	l.referrerMutex.Lock()
+	defer l.referrerMutex.Unlock()
+
+	referrers := make([]Referrer, len(l.referrers[target]))
+	copy(referrers, l.referrers[target])
+
+	sort.Slice(referrers, func(i, j int) bool {
+		if referrers[i].Url != referrers[j].Url {
+			return referrers[i].Url < referrers[j].Url
+		}
+		if referrers[i].Line != referrers[j].Line {
+			return referrers[i].Line < referrers[j].Line
+		}
+		return referrers[i].Column < referrers[j].Column
+	})
+
+	if len(referrers) == 0 {
+		return nil
+	}
+
+	return referrers
+}
+
+// AllReferrers returns the referrers of a result, falling back to the
+// first referring site when every referrer has not been collected
+func (r Result) AllReferrers() []Referrer {
+
+	if len(r.Referrers) > 0 {
+		return r.Referrers
+	}
+
+	return []Referrer{{Url: r.ReferringSite}}
+}
+
+// PageLink is a link on a referring page and the result of checking it
+type PageLink struct {
+	Referrer Referrer
+	Result   Result
+}
+
+// ReferrerGroup lists the checked links found on a single page
+type ReferrerGroup struct {
+	Page  string
+	Links []PageLink
+}
+
+// GroupByReferrer groups results by the pages that link to them, so each
+// page that needs fixing is listed with its links
+func GroupByReferrer(results []Result) []ReferrerGroup {
+
+	pages := make(map[string][]PageLink)
+
+	for _, result := range results {
+		for _, referrer := range result.AllReferrers() {
+			pages[referrer.Url] = append(pages[referrer.Url], PageLink{Referrer: referrer, Result: result})
+		}
+	}
+
+	groups := []ReferrerGroup{}
+	for page, links := range pages {
+		sort.SliceStable(links, func(i, j int) bool { return links[i].Referrer.Line < links[j].Referrer.Line })
+		groups = append(groups, ReferrerGroup{Page: page, Links: links})
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Page < groups[j].Page })
+
+	return groups
+}
@@ -0,0 +1,118 @@
+package linkchecker_test
+
+import (
+	"errors"
+	"io"
+	"linkchecker"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseLinksPositions(t *testing.T) {
+	t.Parallel()
+
+	l, err := linkchecker.NewLinkChecker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Scheme, l.Domain = "https", "example.com"
+
+	r := strings.NewReader(`<html>
+<body>
+  <p>See <a href="/docs">the <b>docs</b>
+  page</a> and <a href="mailto:team@example.com">mail us</a></p>
+<footer><a href="https://other.com/">Other</a></footer>
+</body>`)
+
+	want := []linkchecker.Link{
+		{Url: "https://example.com/docs", Text: "the docs page", Line: 3, Column: 10},
+		{Url: "https://other.com/", Text: "Other", Line: 5, Column: 9},
+	}
+
+	got, err := l.ParseLinks(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Fatal(cmp.Diff(want, got))
+	}
+
+}
+
+func TestParseLinksReadError(t *testing.T) {
+	t.Parallel()
+
+	l, err := linkchecker.NewLinkChecker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Scheme, l.Domain = "https", "example.com"
+
+	lost := errors.New("connection lost")
+	r := io.MultiReader(strings.NewReader(`<p><a href="/docs">docs</a> and more `), iotest.ErrReader(lost))
+
+	want := []linkchecker.Link{
+		{Url: "https://example.com/docs", Text: "docs", Line: 1, Column: 4},
+	}
+
+	got, err := l.ParseLinks(r)
+	if !errors.Is(err, lost) {
+		t.Fatalf("want error %q, got: %v", lost, err)
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Fatal(cmp.Diff(want, got))
+	}
+
+	_, err = l.ParseBody(iotest.ErrReader(lost))
+	if err == nil {
+		t.Fatal("want ParseBody to return the read error, got nil")
+	}
+
+}
+
+func TestGroupByReferrer(t *testing.T) {
+	t.Parallel()
+
+	broken := linkchecker.Result{
+		Url:    "https://example.com/missing",
+		Status: linkchecker.StatusDown,
+		Referrers: []linkchecker.Referrer{
+			{Url: "https://example.com/a", Text: "footer", Line: 40, Column: 3},
+			{Url: "https://example.com/b", Text: "footer", Line: 12, Column: 3},
+		},
+	}
+
+	slow := linkchecker.Result{
+		Url:           "https://slow.com",
+		Status:        linkchecker.StatusTimeout,
+		ReferringSite: "https://example.com/a",
+	}
+
+	want := []linkchecker.ReferrerGroup{
+		{
+			Page: "https://example.com/a",
+			Links: []linkchecker.PageLink{
+				{Referrer: linkchecker.Referrer{Url: "https://example.com/a"}, Result: slow},
+				{Referrer: broken.Referrers[0], Result: broken},
+			},
+		},
+		{
+			Page: "https://example.com/b",
+			Links: []linkchecker.PageLink{
+				{Referrer: broken.Referrers[1], Result: broken},
+			},
+		},
+	}
+
+	got := linkchecker.GroupByReferrer([]linkchecker.Result{broken, slow})
+
+	if !cmp.Equal(want, got) {
+		t.Fatal(cmp.Diff(want, got))
+	}
+
+}
@@ -0,0 +1,157 @@
+package linkchecker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Login describes a form login that is submitted before crawling.  The
+// session cookies it sets are kept in the crawler's cookie jar.
+type Login struct {
+	URL    string            `json:"url"`
+	Fields map[string]string `json:"fields"`
+
+	// the login succeeded if the response has SuccessStatus, 200 by
+	// default, and contains SuccessText when it is set
+	SuccessStatus int    `json:"success_status"`
+	SuccessText   string `json:"success_text"`
+}
+
+func WithLogin(login Login) Option {
+	return func(l *LinkChecker) error {
+		_, err := url.Parse(login.URL)
+		if err != nil {
+			return fmt.Errorf("invalid login url %s, %s", login.URL, err)
+		}
+		l.login = &login
+		return nil
+	}
+}
+
+func LoadLogin(path string) (Login, error) {
+
+	login := Login{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return login, err
+	}
+
+	err = json.Unmarshal(data, &login)
+	if err != nil {
+		return login, fmt.Errorf("unable to parse login config %s, %s", path, err)
+	}
+
+	return login, nil
+}
+
+// Login submits the login form and stores the session cookies
+func (l *LinkChecker) Login() error {
+
+	if l.login == nil {
+		return nil
+	}
+
+	if l.HTTPClient.Jar == nil {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return err
+		}
+		l.HTTPClient.Jar = jar
+	}
+
+	form := url.Values{}
+	for key, value := range l.login.Fields {
+		form.Set(key, value)
+	}
+
+	request, err := l.newRequest(http.MethodPost, l.login.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("content-type", "application/x-www-form-urlencoded")
+
+	resp, err := l.do(request)
+	if err != nil {
+		return fmt.Errorf("unable to log in at %s, %s", l.login.URL, err)
+	}
+	defer resp.Body.Close()
+
+	status := l.login.SuccessStatus
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	if resp.StatusCode != status {
+		return fmt.Errorf("unable to log in at %s, got status %d", l.login.URL, resp.StatusCode)
+	}
+
+	if l.login.SuccessText != "" {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("unable to read login response, %s", err)
+		}
+		if !strings.Contains(string(body), l.login.SuccessText) {
+			return fmt.Errorf("unable to log in at %s, response does not contain %q", l.login.URL, l.login.SuccessText)
+		}
+	}
+
+	return nil
+}
+
+func (l *LinkChecker) sessionGeneration() int {
+
+	l.loginMutex.Lock()
+	defer l.loginMutex.Unlock()
+
+	return l.loginGeneration
+}
+
+// relogin logs in again unless another crawler has already done so since
+// the given generation
+func (l *LinkChecker) relogin(generation int) error {
+
+	l.loginMutex.Lock()
+	defer l.loginMutex.Unlock()
+
+	if l.loginGeneration != generation {
+		return nil
+	}
+
+	err := l.Login()
+	if err != nil {
+		return err
+	}
+
+	l.loginGeneration++
+
+	return nil
+}
+
+// sessionExpired reports whether a page was redirected to the login page
+// because the session is no longer valid.  Pages that return 401 are not
+// taken to mean the session expired, as some are never available.
+func (l *LinkChecker) sessionExpired(site string, resp *http.Response) bool {
+
+	if l.login == nil {
+		return false
+	}
+
+	loginUrl, err := url.Parse(l.login.URL)
+	if err != nil || resp.Request == nil {
+		return false
+	}
+
+	siteUrl, err := url.Parse(site)
+	if err != nil {
+		return false
+	}
+
+	return resp.Request.URL.Path == loginUrl.Path && siteUrl.Path != loginUrl.Path
+}
@@ -0,0 +1,182 @@
+package linkchecker_test
+
+import (
+	"fmt"
+	"linkchecker"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// portal is a test site that requires a session cookie and expires the
+// session once the about page has been served
+type portal struct {
+	mutex     sync.Mutex
+	logins    int
+	redirects int
+	session   string
+}
+
+func (p *portal) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if r.URL.Path == "/login" {
+		if r.Method == http.MethodPost && r.FormValue("user") == "alice" && r.FormValue("password") == "secret" {
+			p.logins++
+			p.session = fmt.Sprintf("session%d", p.logins)
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: p.session, Path: "/"})
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+		w.Write([]byte(`<form method="post"></form>`))
+		return
+	}
+
+	cookie, err := r.Cookie("session")
+	if err != nil || p.session == "" || cookie.Value != p.session {
+		p.redirects++
+		http.Redirect(w, r, "/login", http.StatusFound)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/":
+		w.Write([]byte(`Welcome <a href="about">about</a>`))
+	case "/about":
+		w.Write([]byte(`<a href="home">home</a>`))
+		if r.Method == http.MethodGet {
+			p.session = ""
+		}
+	case "/home":
+		w.Write([]byte(`home`))
+	case "/account":
+		// never available to this user, however recently they logged in
+		w.WriteHeader(http.StatusUnauthorized)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestLoginBeforeCrawl(t *testing.T) {
+	t.Parallel()
+
+	p := &portal{}
+	ts := httptest.NewTLSServer(p)
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithVerboseMode(),
+		linkchecker.WithLogin(linkchecker.Login{
+			URL:         ts.URL + "/login",
+			Fields:      map[string]string{"user": "alice", "password": "secret"},
+			SuccessText: "Welcome",
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, result := range l.GetAllResults() {
+		if result.Status != linkchecker.StatusUp {
+			t.Fatalf("want all pages up, got: %v", result)
+		}
+		if result.Url == ts.URL+"/login" {
+			t.Fatalf("want session to be re-established before checking %s", ts.URL+"/home")
+		}
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.logins != 2 {
+		t.Fatalf("want 2 logins after session expiry, got: %d", p.logins)
+	}
+
+	// the head request for home finds the session expired, so the get
+	// is made with the new session
+	if p.redirects != 1 {
+		t.Fatalf("want 1 redirect to the login page, got: %d", p.redirects)
+	}
+
+}
+
+func TestLoginUnauthorizedPage(t *testing.T) {
+	t.Parallel()
+
+	p := &portal{}
+	ts := httptest.NewTLSServer(p)
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithLogin(linkchecker.Login{
+			URL:    ts.URL + "/login",
+			Fields: map[string]string{"user": "alice", "password": "secret"},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL + "/account")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []linkchecker.Result{
+		{
+			ResponseCode:  http.StatusUnauthorized,
+			Url:           ts.URL + "/account",
+			ReferringSite: ts.URL + "/account",
+			Status:        linkchecker.StatusDown,
+			Problem:       "Non OK response",
+		},
+	}
+
+	got := l.GetAllResults()
+
+	if !cmp.Equal(want, got, ignoreMetrics, ignoreReferrers) {
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics, ignoreReferrers))
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.logins != 1 {
+		t.Fatalf("want a 401 page not to log in again, got: %d logins", p.logins)
+	}
+
+}
+
+func TestLoginFailure(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(&portal{})
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithLogin(linkchecker.Login{
+			URL:         ts.URL + "/login",
+			Fields:      map[string]string{"user": "alice", "password": "wrong"},
+			SuccessText: "Welcome",
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err == nil {
+		t.Fatal("want error for failed login")
+	}
+
+}
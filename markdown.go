@@ -0,0 +1,189 @@
+package linkchecker
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+)
+
+// DefaultMarkdownSize keeps the markdown summary within the size of a
+// GitHub pull request comment
+const DefaultMarkdownSize = 60000
+
+// NewMarkdownReporter returns a reporter writing a compact markdown
+// summary, for posting as a pull request comment.  Broken links are
+// listed by referring page until the output would exceed maxSize bytes.
+// When a baseline report from a previous run is given, links that have
+// broken since are listed first.
+func NewMarkdownReporter(w io.Writer, maxSize int, baseline *Report) Reporter {
+	return markdownReporter{w: w, maxSize: maxSize, baseline: baseline}
+}
+
+type markdownReporter struct {
+	w        io.Writer
+	maxSize  int
+	baseline *Report
+}
+
+func (r markdownReporter) Result(result Result) error {
+	return nil
+}
+
+// Finish writes the summary once every referring page is known
+func (r markdownReporter) Finish(report Report) error {
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "## linkchecker results for %s\n\n", report.Metadata.Site)
+
+	statuses := []Status{}
+	for status := range report.Summary.ByStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i] < statuses[j] })
+
+	b.WriteString("| Status | Links |\n| --- | ---: |\n")
+	for _, status := range statuses {
+		fmt.Fprintf(&b, "| %s | %d |\n", status, report.Summary.ByStatus[status])
+	}
+	fmt.Fprintf(&b, "| **Total** | **%d** |\n", report.Summary.Total)
+
+	broken := []Result{}
+	for _, result := range report.Results {
+		if IsBroken(result.Status) {
+			broken = append(broken, result)
+		}
+	}
+
+	sections := []string{}
+
+	if r.baseline != nil {
+		room := -1
+		if r.maxSize > 0 {
+			room = r.maxSize - b.Len() - 100
+			if room < 0 {
+				room = 0
+			}
+		}
+		sections = append(sections, markdownNewSince(broken, r.baseline.Results, room))
+	}
+
+	groups := GroupByReferrer(broken)
+	if len(groups) == 0 {
+		sections = append(sections, "\nNo broken links found.\n")
+	}
+
+	for _, group := range groups {
+		var section strings.Builder
+		fmt.Fprintf(&section, "\n<details>\n<summary>%s (%d broken)</summary>\n\n", html.EscapeString(group.Page), len(group.Links))
+		for _, link := range group.Links {
+			section.WriteString(markdownLink(link, false))
+		}
+		section.WriteString("\n</details>\n")
+		sections = append(sections, section.String())
+	}
+
+	// sections are added while they fit, leaving room for the note
+	// about the ones left out
+	for i, section := range sections {
+		if r.maxSize > 0 && b.Len()+len(section)+100 > r.maxSize {
+			fmt.Fprintf(&b, "\n_%d more sections not shown to keep this summary short._\n", len(sections)-i)
+			break
+		}
+		b.WriteString(section)
+	}
+
+	_, err := io.WriteString(r.w, b.String())
+	return err
+}
+
+// markdownNewSince lists the broken links that were not broken in the
+// baseline results, cutting the list short to keep within room bytes, or
+// with no limit when room is negative
+func markdownNewSince(broken []Result, baseline []Result, room int) string {
+
+	before := map[[2]string]bool{}
+	for _, result := range baseline {
+		if IsBroken(result.Status) {
+			for _, referrer := range result.AllReferrers() {
+				before[[2]string{result.Url, referrer.Url}] = true
+			}
+		}
+	}
+
+	links := []string{}
+	for _, group := range GroupByReferrer(broken) {
+		for _, link := range group.Links {
+			if !before[[2]string{link.Result.Url, group.Page}] {
+				links = append(links, markdownLink(link, true))
+			}
+		}
+	}
+
+	if len(links) == 0 {
+		return "\n### New since baseline\n\nNo new broken links.\n"
+	}
+
+	heading := fmt.Sprintf("\n### New since baseline (%d)\n\n", len(links))
+
+	// leave room for the note about the links left out
+	if room >= 0 && len(heading)+100 > room {
+		return fmt.Sprintf("\n_%d new broken links since the baseline not shown to keep this summary short._\n", len(links))
+	}
+
+	var b strings.Builder
+	b.WriteString(heading)
+
+	for i, link := range links {
+		if room >= 0 && b.Len()+len(link)+100 > room {
+			fmt.Fprintf(&b, "\n_%d more new broken links not shown to keep this summary short._\n", len(links)-i)
+			break
+		}
+		b.WriteString(link)
+	}
+
+	return b.String()
+}
+
+// markdownLink writes a list item for a broken link, naming the page it
+// is on unless the list is already grouped by page
+func markdownLink(link PageLink, withPage bool) string {
+
+	line := fmt.Sprintf("- <%s>", link.Result.Url)
+	if withPage {
+		line += fmt.Sprintf(" on <%s>", link.Referrer.Url)
+	}
+	if link.Referrer.Line > 0 {
+		line += fmt.Sprintf(" line %d", link.Referrer.Line)
+	}
+	if link.Referrer.Text != "" {
+		line += fmt.Sprintf(" \"%s\"", markdownEscape(link.Referrer.Text))
+	}
+
+	line += fmt.Sprintf(": %s", link.Result.Status)
+	if link.Result.ResponseCode != 0 {
+		line += fmt.Sprintf(" %d", link.Result.ResponseCode)
+	}
+	if link.Result.Problem != "" {
+		line += ", " + markdownEscape(link.Result.Problem)
+	}
+
+	return line + "\n"
+}
+
+// markdownEscape keeps text from the page from being read as markdown
+// or html
+var markdownEscape = strings.NewReplacer(
+	"\n", " ",
+	"\\", "\\\\",
+	"`", "\\`",
+	"*", "\\*",
+	"_", "\\_",
+	"[", "\\[",
+	"]", "\\]",
+	"|", "\\|",
+	"<", "&lt;",
+	">", "&gt;",
+).Replace
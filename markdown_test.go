@@ -0,0 +1,163 @@
+package linkchecker_test
+
+import (
+	"bytes"
+	"fmt"
+	"linkchecker"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownReporter(t *testing.T) {
+	t.Parallel()
+
+	broken := func(url, page string) linkchecker.Result {
+		return linkchecker.Result{
+			ResponseCode:  http.StatusNotFound,
+			Url:           url,
+			ReferringSite: page,
+			Problem:       "Non OK response",
+			Status:        linkchecker.StatusDown,
+			Referrers:     []linkchecker.Referrer{{Url: page, Text: "a | b", Line: 3}},
+		}
+	}
+
+	report := linkchecker.Report{
+		Metadata: linkchecker.Metadata{Site: "https://example.com"},
+		Summary: linkchecker.Summary{
+			Total:    5,
+			ByStatus: map[linkchecker.Status]int{linkchecker.StatusUp: 3, linkchecker.StatusDown: 2},
+		},
+		Results: []linkchecker.Result{
+			broken("https://example.com/old", "https://example.com/about"),
+			broken("https://example.com/new", "https://example.com/docs"),
+		},
+	}
+
+	baseline := &linkchecker.Report{
+		Results: []linkchecker.Result{
+			broken("https://example.com/old", "https://example.com/about"),
+		},
+	}
+
+	var output bytes.Buffer
+
+	err := linkchecker.NewMarkdownReporter(&output, linkchecker.DefaultMarkdownSize, baseline).Finish(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := output.String()
+
+	wants := []string{
+		"| Up | 3 |\n| Down | 2 |\n| **Total** | **5** |\n",
+		"### New since baseline (1)\n\n- <https://example.com/new> on <https://example.com/docs> line 3 \"a \\| b\": Down 404, Non OK response\n",
+		"<summary>https://example.com/about (1 broken)</summary>\n\n- <https://example.com/old> line 3 \"a \\| b\": Down 404, Non OK response\n",
+	}
+
+	for _, want := range wants {
+		if !strings.Contains(got, want) {
+			t.Fatalf("want summary to contain %q, got:\n%s", want, got)
+		}
+	}
+
+}
+
+func TestMarkdownReporterSize(t *testing.T) {
+	t.Parallel()
+
+	report := linkchecker.Report{}
+	for i := 0; i < 100; i++ {
+		page := fmt.Sprintf("https://example.com/page%d", i)
+		report.Results = append(report.Results, linkchecker.Result{
+			Url:           page + "/missing",
+			ReferringSite: page,
+			Status:        linkchecker.StatusDown,
+		})
+	}
+
+	var output bytes.Buffer
+
+	err := linkchecker.NewMarkdownReporter(&output, 2000, nil).Finish(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if output.Len() > 2000 {
+		t.Fatalf("want summary within 2000 bytes, got: %d", output.Len())
+	}
+
+	if !strings.Contains(output.String(), "more sections not shown") {
+		t.Fatalf("want note about pages left out, got:\n%s", output.String())
+	}
+
+}
+
+func TestMarkdownReporterBaselineSize(t *testing.T) {
+	t.Parallel()
+
+	report := linkchecker.Report{}
+	for i := 0; i < 100; i++ {
+		page := fmt.Sprintf("https://example.com/page%d", i)
+		report.Results = append(report.Results, linkchecker.Result{
+			Url:           page + "/missing",
+			ReferringSite: page,
+			Status:        linkchecker.StatusDown,
+		})
+	}
+
+	var output bytes.Buffer
+
+	err := linkchecker.NewMarkdownReporter(&output, 2000, &linkchecker.Report{}).Finish(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := output.String()
+
+	if output.Len() > 2000 {
+		t.Fatalf("want summary within 2000 bytes, got: %d", output.Len())
+	}
+
+	for _, want := range []string{
+		"### New since baseline (100)",
+		"- <https://example.com/page0/missing> on <https://example.com/page0>",
+		"more new broken links not shown",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("want summary to contain %q, got:\n%s", want, got)
+		}
+	}
+
+}
+
+func TestMarkdownReporterNoRoomForBaseline(t *testing.T) {
+	t.Parallel()
+
+	report := linkchecker.Report{
+		Summary: linkchecker.Summary{Total: 1, ByStatus: map[linkchecker.Status]int{linkchecker.StatusDown: 1}},
+		Results: []linkchecker.Result{
+			{Url: "https://example.com/missing", ReferringSite: "https://example.com", Status: linkchecker.StatusDown},
+		},
+	}
+
+	var output bytes.Buffer
+
+	// the heading and counts alone leave no room for the baseline section
+	err := linkchecker.NewMarkdownReporter(&output, 200, &linkchecker.Report{}).Finish(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := output.String()
+
+	if output.Len() > 200 {
+		t.Fatalf("want summary within 200 bytes, got: %d", output.Len())
+	}
+
+	if strings.Contains(got, "### New since baseline") || !strings.Contains(got, "not shown") {
+		t.Fatalf("want only a note about the sections left out, got:\n%s", got)
+	}
+
+}
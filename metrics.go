@@ -0,0 +1,151 @@
+package linkchecker
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Metrics holds the timings and size of the response for a link.  Total
+// runs until the body is read for pages that are parsed, otherwise until
+// the response headers arrive.  DNS, Connect and TLS are from the request
+// that opened the connection, which is the HEAD when the GET reuses it.
+type Metrics struct {
+	DNS             time.Duration `json:"dns"`
+	Connect         time.Duration `json:"connect"`
+	TLS             time.Duration `json:"tls"`
+	TimeToFirstByte time.Duration `json:"time_to_first_byte"`
+	Total           time.Duration `json:"total"`
+	ContentLength   int64         `json:"content_length"`
+	ContentType     string        `json:"content_type,omitempty"`
+}
+
+func WithLatencyThreshold(threshold time.Duration) Option {
+	return func(l *LinkChecker) error {
+		l.latencyThreshold = threshold
+		return nil
+	}
+}
+
+// SlowestResults returns up to n results ordered by total response time
+func SlowestResults(results []Result, n int) []Result {
+
+	slowest := make([]Result, len(results))
+	copy(slowest, results)
+
+	sort.SliceStable(slowest, func(i, j int) bool { return slowest[i].Metrics.Total > slowest[j].Metrics.Total })
+
+	if n < len(slowest) {
+		slowest = slowest[:n]
+	}
+
+	return slowest
+}
+
+// latencyWarning returns a warning if the response took longer than the
+// configured threshold
+func (l *LinkChecker) latencyWarning(metrics Metrics) []string {
+
+	if l.latencyThreshold <= 0 || metrics.Total <= l.latencyThreshold {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("slow response, took %s which exceeds %s", metrics.Total.Round(time.Millisecond), l.latencyThreshold)}
+}
+
+// withConnection fills in the connection timings from an earlier request,
+// as a request on a reused connection has none of its own
+func (m Metrics) withConnection(earlier Metrics) Metrics {
+
+	if m.DNS == 0 && m.Connect == 0 && m.TLS == 0 {
+		m.DNS = earlier.DNS
+		m.Connect = earlier.Connect
+		m.TLS = earlier.TLS
+	}
+
+	return m
+}
+
+// requestTrace records the timings of a request using httptrace
+type requestTrace struct {
+	mutex        sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	metrics      Metrics
+}
+
+func newRequestTrace(request *http.Request) (*http.Request, *requestTrace) {
+
+	t := &requestTrace{start: time.Now()}
+
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			t.metrics.DNS = time.Since(t.dnsStart)
+		},
+		ConnectStart: func(network, addr string) {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			if err == nil && t.metrics.Connect == 0 {
+				t.metrics.Connect = time.Since(t.connectStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			t.metrics.TLS = time.Since(t.tlsStart)
+		},
+		GotFirstResponseByte: func() {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			t.metrics.TimeToFirstByte = time.Since(t.start)
+		},
+	}
+
+	return request.WithContext(httptrace.WithClientTrace(request.Context(), trace)), t
+}
+
+// done returns the metrics for the request so far, recording size as the
+// content length, or 0 when it is unknown
+func (t *requestTrace) done(resp *http.Response, size int64) Metrics {
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if size < 0 {
+		size = 0
+	}
+
+	t.metrics.Total = time.Since(t.start)
+	t.metrics.ContentLength = size
+
+	if resp != nil {
+		t.metrics.ContentType = resp.Header.Get("content-type")
+	}
+
+	return t.metrics
+}
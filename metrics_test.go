@@ -0,0 +1,129 @@
+package linkchecker_test
+
+import (
+	"linkchecker"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResultMetrics(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Header().Set("content-type", "text/html; charset=utf-8")
+		w.Write([]byte(`<a href="slow">slow</a>`))
+	}))
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithVerboseMode(),
+		linkchecker.WithLatencyThreshold(100*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := l.GetAllResults()
+	if len(results) != 2 {
+		t.Fatalf("want 2 results, got: %d", len(results))
+	}
+
+	page, slow := results[0], results[1]
+
+	if page.Metrics.ContentType != "text/html; charset=utf-8" {
+		t.Fatalf("want content type recorded, got: %q", page.Metrics.ContentType)
+	}
+
+	if page.Metrics.ContentLength != int64(len(`<a href="slow">slow</a>`)) {
+		t.Fatalf("want content length recorded, got: %d", page.Metrics.ContentLength)
+	}
+
+	// the get reuses the connection the head request opened
+	if page.Metrics.Connect <= 0 || page.Metrics.TLS <= 0 {
+		t.Fatalf("want connect and tls handshake times recorded, got: %+v", page.Metrics)
+	}
+
+	if page.Metrics.TimeToFirstByte <= 0 || page.Metrics.Total < page.Metrics.TimeToFirstByte {
+		t.Fatalf("want time to first byte within total, got: %+v", page.Metrics)
+	}
+
+	if len(page.Warnings) != 0 {
+		t.Fatalf("want no warnings for fast page, got: %q", page.Warnings)
+	}
+
+	if len(slow.Warnings) != 1 || !strings.HasPrefix(slow.Warnings[0], "slow response") {
+		t.Fatalf("want slow response warning, got: %q", slow.Warnings)
+	}
+
+}
+
+func TestResultMetricsUnknownContentLength(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			// a chunked response has no content length
+			w.WriteHeader(http.StatusNotFound)
+			w.(http.Flusher).Flush()
+			w.Write([]byte(`gone`))
+			return
+		}
+		w.Write([]byte(`<a href="gone">gone</a>`))
+	}))
+
+	l, err := linkchecker.NewLinkChecker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := l.GetAllResults()
+	if len(results) != 1 {
+		t.Fatalf("want 1 result, got: %d", len(results))
+	}
+
+	if results[0].Metrics.ContentLength != 0 {
+		t.Fatalf("want unknown content length recorded as 0, got: %d", results[0].Metrics.ContentLength)
+	}
+
+}
+
+func TestSlowestResults(t *testing.T) {
+	t.Parallel()
+
+	results := []linkchecker.Result{
+		{Url: "https://example.com/a", Metrics: linkchecker.Metrics{Total: 10 * time.Millisecond}},
+		{Url: "https://example.com/b", Metrics: linkchecker.Metrics{Total: 300 * time.Millisecond}},
+		{Url: "https://example.com/c", Metrics: linkchecker.Metrics{Total: 50 * time.Millisecond}},
+	}
+
+	want := []string{"https://example.com/b", "https://example.com/c"}
+
+	got := []string{}
+	for _, result := range linkchecker.SlowestResults(results, 2) {
+		got = append(got, result.Url)
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Fatal(cmp.Diff(want, got))
+	}
+
+}
@@ -0,0 +1,87 @@
+package linkchecker
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// Middleware wraps the transport used for every request, for example to
+// sign requests, record traffic or collect metrics
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to an http.RoundTripper
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// WithMiddleware adds middleware around the transport of HTTPClient.  The
+// first middleware given is the outermost.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(l *LinkChecker) error {
+		l.middleware = append(l.middleware, middleware...)
+		return nil
+	}
+}
+
+// RequestOverride changes the requests made for links matching Pattern.
+// Empty fields are left unchanged.  Method replaces the HEAD request
+// that checks a link, the GET that reads a page for its links is sent as
+// a GET.
+type RequestOverride struct {
+	Pattern *regexp.Regexp
+	Method  string
+	Header  http.Header
+	Timeout time.Duration
+}
+
+// WithRequestOverrides adds overrides that are tried in order, the first
+// override matching a link is applied
+func WithRequestOverrides(overrides ...RequestOverride) Option {
+	return func(l *LinkChecker) error {
+		l.overrides = append(l.overrides, overrides...)
+		return nil
+	}
+}
+
+// do sends a request through any middleware, applying the first
+// matching request override.  file:// urls are served from disk.
+func (l *LinkChecker) do(request *http.Request) (*http.Response, error) {
+
+	client := *l.HTTPClient
+
+	for _, override := range l.overrides {
+		if override.Pattern == nil || !override.Pattern.MatchString(request.URL.String()) {
+			continue
+		}
+		if override.Method != "" && request.Method == http.MethodHead {
+			request.Method = override.Method
+		}
+		for key, values := range override.Header {
+			request.Header[http.CanonicalHeaderKey(key)] = values
+		}
+		if override.Timeout > 0 {
+			client.Timeout = override.Timeout
+		}
+		break
+	}
+
+	if request.URL.Scheme == "file" {
+		client.Transport = fileTransport{root: l.fileRoot}
+	}
+
+	if len(l.middleware) > 0 {
+		transport := client.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		for i := len(l.middleware) - 1; i >= 0; i-- {
+			transport = l.middleware[i](transport)
+		}
+		client.Transport = transport
+	}
+
+	return client.Do(request)
+}
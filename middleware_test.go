@@ -0,0 +1,142 @@
+package linkchecker_test
+
+import (
+	"linkchecker"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-signature") != "signed:"+r.Host {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`<a href="about">about</a>`))
+	}))
+
+	var requests int32
+
+	sign := func(next http.RoundTripper) http.RoundTripper {
+		return linkchecker.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			r = r.Clone(r.Context())
+			r.Header.Set("x-signature", "signed:"+r.URL.Host)
+			return next.RoundTrip(r)
+		})
+	}
+
+	count := func(next http.RoundTripper) http.RoundTripper {
+		return linkchecker.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&requests, 1)
+			return next.RoundTrip(r)
+		})
+	}
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithMiddleware(count, sign),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := l.GetAllResults()
+	if len(got) != 0 {
+		t.Fatalf("want all requests signed, got: %v", got)
+	}
+
+	// a head and a get request for each of the two pages
+	if atomic.LoadInt32(&requests) != 4 {
+		t.Fatalf("want 4 requests, got: %d", atomic.LoadInt32(&requests))
+	}
+
+}
+
+func TestRequestOverrides(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<a href="no-head">no head</a><a href="slow">slow</a>`))
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		case "/slow":
+			time.Sleep(500 * time.Millisecond)
+		}
+	}))
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithRequestOverrides(
+			linkchecker.RequestOverride{Pattern: regexp.MustCompile("/no-head$"), Method: http.MethodGet},
+			linkchecker.RequestOverride{Pattern: regexp.MustCompile("/slow$"), Timeout: 50 * time.Millisecond},
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := l.GetAllResults()
+
+	if len(got) != 1 || got[0].Url != ts.URL+"/slow" || got[0].Status != linkchecker.StatusTimeout {
+		t.Fatalf("want only the slow link to time out, got: %v", got)
+	}
+
+}
+
+func TestRequestOverrideMethodOnlyChangesCheck(t *testing.T) {
+	t.Parallel()
+
+	// the page is still read with a GET, so its broken link is found
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<a href="docs">docs</a>`))
+		case "/docs":
+			w.Write([]byte(`<a href="missing">missing</a>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithRequestOverrides(
+			linkchecker.RequestOverride{Pattern: regexp.MustCompile("/docs$"), Method: http.MethodHead},
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := l.GetAllResults()
+
+	if len(got) != 1 || got[0].Url != ts.URL+"/missing" || got[0].Status != linkchecker.StatusDown {
+		t.Fatalf("want only the missing link to be down, got: %v", got)
+	}
+
+}
@@ -0,0 +1,164 @@
+package linkchecker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+type PolicyAction string
+
+const (
+	PolicyCheck PolicyAction = ""
+	PolicySkip  PolicyAction = "skip"
+)
+
+// PolicyRule changes how links matching Pattern are checked.  Links can
+// be skipped, have extra status codes accepted as up, or have their
+// status forced to a given value.
+type PolicyRule struct {
+	Pattern     string       `json:"pattern"`
+	AcceptCodes []int        `json:"accept_codes,omitempty"`
+	ForceStatus string       `json:"force_status,omitempty"`
+	Action      PolicyAction `json:"action,omitempty"`
+	Note        string       `json:"note,omitempty"`
+
+	pattern *regexp.Regexp
+	force   Status
+}
+
+// defaultPolicyRules are checked after any user rules
+var defaultPolicyRules = compileRules([]PolicyRule{
+	{
+		Pattern:     `^https?://([^/]+\.)?linkedin\.com(:\d+)?(/|$)`,
+		AcceptCodes: []int{999},
+		Note:        "linkedin is up, but rejects http requests",
+	},
+})
+
+func WithPolicyRules(rules ...PolicyRule) Option {
+	return func(l *LinkChecker) error {
+		for _, rule := range rules {
+			err := rule.compile()
+			if err != nil {
+				return err
+			}
+			l.policyRules = append(l.policyRules, rule)
+		}
+		return nil
+	}
+}
+
+func WithPolicyFile(path string) Option {
+	return func(l *LinkChecker) error {
+		rules, err := LoadPolicyRules(path)
+		if err != nil {
+			return err
+		}
+		return WithPolicyRules(rules...)(l)
+	}
+}
+
+// LoadPolicyRules reads a json array of rules from path
+func LoadPolicyRules(path string) ([]PolicyRule, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []PolicyRule{}
+
+	err = json.Unmarshal(data, &rules)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse rules file %s, %s", path, err)
+	}
+
+	return rules, nil
+}
+
+func (r *PolicyRule) compile() error {
+
+	pattern, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid rule pattern %q, %s", r.Pattern, err)
+	}
+	r.pattern = pattern
+
+	if r.ForceStatus != "" {
+		r.force, err = ParseStatus(r.ForceStatus)
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.Action != PolicyCheck && r.Action != PolicySkip {
+		return fmt.Errorf("invalid rule action %q for pattern %q", r.Action, r.Pattern)
+	}
+
+	return nil
+}
+
+func (r *PolicyRule) accepts(code int) bool {
+
+	if r == nil {
+		return false
+	}
+
+	for _, accepted := range r.AcceptCodes {
+		if accepted == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchPolicy returns the first rule matching link, checking user rules
+// before the defaults
+func (l *LinkChecker) matchPolicy(link string) *PolicyRule {
+
+	for i := range l.policyRules {
+		if l.policyRules[i].pattern.MatchString(link) {
+			return &l.policyRules[i]
+		}
+	}
+
+	for i := range defaultPolicyRules {
+		if defaultPolicyRules[i].pattern.MatchString(link) {
+			return &defaultPolicyRules[i]
+		}
+	}
+
+	return nil
+}
+
+func compileRules(rules []PolicyRule) []PolicyRule {
+
+	for i := range rules {
+		err := rules[i].compile()
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return rules
+}
+
+func ParseStatus(status string) (Status, error) {
+
+	// linkedin's code is easier to remember than the status name
+	if status == "999" {
+		return Status999, nil
+	}
+
+	for s, str := range StatusStringMap {
+		if s != None && strings.EqualFold(str, status) {
+			return s, nil
+		}
+	}
+
+	return None, fmt.Errorf("unknown status %q", status)
+}
@@ -0,0 +1,120 @@
+package linkchecker_test
+
+import (
+	"linkchecker"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPolicyRulesFile(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<a href="bot">bot</a><a href="account">account</a><a href="calendar">calendar</a><a href="legacy">legacy</a><a href="busy">busy</a>`))
+		case "/bot":
+			w.WriteHeader(http.StatusForbidden)
+		case "/account":
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/busy":
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/calendar":
+			t.Errorf("skipped link %s was requested", r.URL.Path)
+		}
+	}))
+
+	rules := `[
+		{"pattern": "/bot$", "accept_codes": [403]},
+		{"pattern": "/account$", "accept_codes": [401], "note": "login required"},
+		{"pattern": "/busy$", "accept_codes": [429], "note": "rate limits crawlers"},
+		{"pattern": "/calendar", "action": "skip"},
+		{"pattern": "/legacy$", "force_status": "down", "note": "legacy pages must be removed"}
+	]`
+
+	path := filepath.Join(t.TempDir(), "rules.json")
+	err := os.WriteFile(path, []byte(rules), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithVerboseMode(),
+		linkchecker.WithPolicyFile(path),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []linkchecker.Result{
+		{ResponseCode: http.StatusOK, Url: ts.URL, ReferringSite: ts.URL, Status: linkchecker.StatusUp},
+		{ResponseCode: http.StatusUnauthorized, Url: ts.URL + "/account", ReferringSite: ts.URL, Status: linkchecker.StatusUp, Problem: "login required"},
+		{ResponseCode: http.StatusForbidden, Url: ts.URL + "/bot", ReferringSite: ts.URL, Status: linkchecker.StatusUp},
+		{ResponseCode: http.StatusTooManyRequests, Url: ts.URL + "/busy", ReferringSite: ts.URL, Status: linkchecker.StatusUp, Problem: "rate limits crawlers"},
+		{Url: ts.URL + "/calendar", ReferringSite: ts.URL, Status: linkchecker.StatusSkipped, Problem: "Skipped by policy rule"},
+		{ResponseCode: http.StatusOK, Url: ts.URL + "/legacy", ReferringSite: ts.URL, Status: linkchecker.StatusDown, Problem: "legacy pages must be removed"},
+	}
+
+	got := l.GetAllResults()
+
+	if !cmp.Equal(want, got, ignoreMetrics, ignoreReferrers) {
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics, ignoreReferrers))
+	}
+
+}
+
+func TestDefaultLinkedinRule(t *testing.T) {
+	t.Parallel()
+
+	linkedin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(999)
+	}))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<a href="http://www.linkedin.com/in/someone">profile</a>`))
+	}))
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithVerboseMode(),
+		linkchecker.WithResolve("www.linkedin.com:80", linkedin.Listener.Addr().String()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := linkchecker.Result{
+		ResponseCode:  999,
+		Url:           "http://www.linkedin.com/in/someone",
+		ReferringSite: ts.URL,
+		Status:        linkchecker.StatusUp,
+		Problem:       "linkedin is up, but rejects http requests",
+	}
+
+	var got linkchecker.Result
+	for _, result := range l.GetAllResults() {
+		if result.Url == want.Url {
+			got = result
+		}
+	}
+
+	if !cmp.Equal(want, got, ignoreMetrics, ignoreReferrers) {
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics, ignoreReferrers))
+	}
+
+}
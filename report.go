@@ -0,0 +1,144 @@
+package linkchecker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Report is the complete outcome of a crawl, as written by the json
+// format
+type Report struct {
+	Metadata Metadata `json:"metadata"`
+	Summary  Summary  `json:"summary"`
+	Results  []Result `json:"results"`
+}
+
+// Metadata describes the crawl a report was produced by
+type Metadata struct {
+	Tool         string            `json:"tool"`
+	Site         string            `json:"site"`
+	StartedAt    time.Time         `json:"started_at"`
+	FinishedAt   time.Time         `json:"finished_at"`
+	Verbose      bool              `json:"verbose"`
+	Certificates []CertificateInfo `json:"certificates,omitempty"`
+}
+
+// Reporter writes results in a machine readable format
+type Reporter interface {
+	// Result is called with each result as it arrives
+	Result(result Result) error
+	// Finish is called with the complete report once the crawl is done
+	Finish(report Report) error
+}
+
+// NewReporter returns the reporter for format, writing to w
+func NewReporter(format string, w io.Writer) (Reporter, error) {
+
+	switch format {
+	case "json":
+		return jsonReporter{w: w}, nil
+	case "jsonl":
+		return jsonlReporter{encoder: json.NewEncoder(w)}, nil
+	case "csv":
+		return NewCSVReporter(w)
+	case "tsv":
+		return NewTSVReporter(w)
+	case "junit":
+		return NewJUnitReporter(w), nil
+	case "sarif":
+		return NewSARIFReporter(w, nil), nil
+	case "html":
+		return NewHTMLReporter(w), nil
+	case "markdown":
+		return NewMarkdownReporter(w, DefaultMarkdownSize, nil), nil
+	case "github":
+		return NewGitHubReporter(w, nil), nil
+	case "gitlab":
+		return NewGitLabReporter(w, nil), nil
+	}
+
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// IsBroken reports whether a link with status is broken, or could not
+// be shown to work
+func IsBroken(status Status) bool {
+
+	switch status {
+	case StatusDown, StatusTimeout, StatusSoft404, StatusRateLimited, Status999:
+		return true
+	}
+
+	return false
+}
+
+// Report collects the results of the crawl along with its summary and
+// metadata
+func (l *LinkChecker) Report(results []Result) Report {
+
+	summary := l.Summary()
+
+	return Report{
+		Metadata: Metadata{
+			Tool:         "linkchecker",
+			Site:         summary.Site,
+			StartedAt:    summary.StartedAt,
+			FinishedAt:   summary.StartedAt.Add(summary.Duration),
+			Verbose:      l.verboseMode,
+			Certificates: l.Certificates(),
+		},
+		Summary: summary,
+		Results: results,
+	}
+}
+
+// LoadReport reads a report written by the json format, such as the
+// results of a previous run
+func LoadReport(path string) (Report, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Report{}, fmt.Errorf("unable to read report %s, %s", path, err)
+	}
+
+	report := Report{}
+	err = json.Unmarshal(data, &report)
+	if err != nil {
+		return Report{}, fmt.Errorf("unable to parse report %s, %s", path, err)
+	}
+
+	return report, nil
+}
+
+// jsonReporter writes a single document once the crawl is done
+type jsonReporter struct {
+	w io.Writer
+}
+
+func (r jsonReporter) Result(result Result) error {
+	return nil
+}
+
+func (r jsonReporter) Finish(report Report) error {
+
+	encoder := json.NewEncoder(r.w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
+
+// jsonlReporter streams one result per line as they arrive
+type jsonlReporter struct {
+	encoder *json.Encoder
+}
+
+func (r jsonlReporter) Result(result Result) error {
+	return r.encoder.Encode(result)
+}
+
+func (r jsonlReporter) Finish(report Report) error {
+	return nil
+}
@@ -0,0 +1,140 @@
+package linkchecker_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"linkchecker"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStatusJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(linkchecker.Result{Status: linkchecker.StatusRateLimited, ErrorKind: linkchecker.ErrorTimeout})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), `"status":"RateLimited"`) || !strings.Contains(string(data), `"error_kind":"Timeout"`) {
+		t.Fatalf("want status and error kind marshalled by name, got: %s", data)
+	}
+
+	var result linkchecker.Result
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Status != linkchecker.StatusRateLimited || result.ErrorKind != linkchecker.ErrorTimeout {
+		t.Fatalf("want status and error kind to round trip, got: %v %v", result.Status, result.ErrorKind)
+	}
+
+	// numeric statuses are still accepted
+	err = json.Unmarshal([]byte(`{"status": 2}`), &result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Status != linkchecker.StatusDown {
+		t.Fatalf("want status Down, got: %v", result.Status)
+	}
+
+}
+
+func TestJSONReporter(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`<a href="/missing">missing</a>`))
+	}))
+
+	l, err := linkchecker.NewLinkChecker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	var jsonOutput, jsonlOutput bytes.Buffer
+
+	jsonReporter, err := linkchecker.NewReporter("json", &jsonOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jsonlReporter, err := linkchecker.NewReporter("jsonl", &jsonlOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := l.GetAllResults()
+	for _, result := range results {
+		jsonlReporter.Result(result)
+	}
+
+	err = jsonReporter.Finish(l.Report(results))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "results.json")
+	err = os.WriteFile(path, jsonOutput.Bytes(), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := linkchecker.LoadReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(results, report.Results) {
+		t.Fatal(cmp.Diff(results, report.Results))
+	}
+
+	want := map[linkchecker.Status]int{linkchecker.StatusUp: 1, linkchecker.StatusDown: 1}
+	if !cmp.Equal(want, report.Summary.ByStatus) {
+		t.Fatal(cmp.Diff(want, report.Summary.ByStatus))
+	}
+
+	if report.Metadata.Site != ts.URL {
+		t.Fatalf("want metadata site %s, got: %s", ts.URL, report.Metadata.Site)
+	}
+
+	lines := 0
+	scanner := bufio.NewScanner(&jsonlOutput)
+	for scanner.Scan() {
+		var result linkchecker.Result
+		err = json.Unmarshal(scanner.Bytes(), &result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines++
+	}
+
+	if lines != len(results) {
+		t.Fatalf("want %d lines, got: %d", len(results), lines)
+	}
+
+	_, err = linkchecker.NewReporter("yaml", &jsonOutput)
+	if err == nil {
+		t.Fatal("want error for unknown format")
+	}
+
+}
@@ -0,0 +1,58 @@
+package linkchecker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// WithResolve sends connections for hostPort to address instead of the
+// address found by DNS, like curl --resolve.  The address may omit the
+// port, in which case the port of hostPort is used.  It configures the
+// transport of HTTPClient, so set HTTPClient before applying it rather
+// than replacing it afterwards.
+func WithResolve(hostPort, address string) Option {
+	return func(l *LinkChecker) error {
+		_, port, err := net.SplitHostPort(hostPort)
+		if err != nil {
+			return fmt.Errorf("invalid resolve host %q, expected host:port, %s", hostPort, err)
+		}
+
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			address = net.JoinHostPort(strings.Trim(address, "[]"), port)
+		}
+
+		if l.resolve == nil {
+			l.resolve = make(map[string]string)
+
+			dialer := &net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}
+
+			l.transport().DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+				if override, ok := l.resolve[strings.ToLower(addr)]; ok {
+					addr = override
+				}
+				return dialer.DialContext(ctx, network, addr)
+			}
+		}
+
+		l.resolve[strings.ToLower(hostPort)] = address
+		return nil
+	}
+}
+
+// ParseResolve splits a host:port=address override as given on the
+// command line
+func ParseResolve(value string) (string, string, error) {
+
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid resolve %q, expected host:port=address", value)
+	}
+
+	return parts[0], parts[1], nil
+}
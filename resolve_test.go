@@ -0,0 +1,64 @@
+package linkchecker_test
+
+import (
+	"linkchecker"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResolveOverride(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.FileServer(http.Dir("./testdata")))
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithResolve("staging.example.com:80", ts.Listener.Addr().String()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = l.Check("http://staging.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []linkchecker.Result{
+		{
+			ResponseCode:  http.StatusNotFound,
+			Url:           "http://staging.example.com/zzz",
+			ReferringSite: "http://staging.example.com/about",
+			Status:        linkchecker.StatusDown,
+			Problem:       "Non OK response",
+		},
+	}
+
+	got := l.GetAllResults()
+
+	if !cmp.Equal(want, got, ignoreMetrics, ignoreReferrers) {
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics, ignoreReferrers))
+	}
+
+}
+
+func TestParseResolve(t *testing.T) {
+	t.Parallel()
+
+	hostPort, address, err := linkchecker.ParseResolve("example.com:443=10.0.0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hostPort != "example.com:443" || address != "10.0.0.5" {
+		t.Fatalf("want: example.com:443 10.0.0.5, got: %s %s", hostPort, address)
+	}
+
+	_, _, err = linkchecker.ParseResolve("example.com:443")
+	if err == nil {
+		t.Fatal("want error for missing address")
+	}
+
+}
@@ -0,0 +1,75 @@
+package linkchecker
+
+import (
+	"net/http"
+	"time"
+)
+
+// WithRetries requests links again when they fail in a way that may be
+// temporary, such as a timeout, a reset connection, a 429 or a 5xx
+// response, waiting delay times the attempt number between tries
+func WithRetries(retries int, delay time.Duration) Option {
+	return func(l *LinkChecker) error {
+		l.retries = retries
+		l.retryDelay = delay
+		return nil
+	}
+}
+
+// retryable reports whether a failed request might succeed if made again.
+// Errors that are not known to be temporary, such as a malformed request,
+// are not retried.
+func retryable(code int, err error) bool {
+
+	if err != nil {
+		switch ClassifyError(err) {
+		case ErrorTimeout, ErrorConnectionRefused, ErrorConnectionReset:
+			return true
+		}
+		return false
+	}
+
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// headWithRetries makes a head request, retrying failures that may be
+// temporary, and returns how many retries were needed
+func (l *LinkChecker) headWithRetries(link string) (*http.Response, Metrics, int, error) {
+
+	retries := 0
+
+	for {
+		resp, metrics, err := l.head(link)
+
+		// a 501 means the server doesn't support head requests, so the
+		// get request that follows is what decides the result
+		if retries >= l.retries || !retryable(resp.StatusCode, err) || resp.StatusCode == http.StatusNotImplemented {
+			return resp, metrics, retries, err
+		}
+
+		retries++
+		time.Sleep(l.retryDelay * time.Duration(retries))
+	}
+}
+
+// getWithRetries makes a get request, retrying failures that may be
+// temporary up to maxRetries times, and returns how many retries were
+// needed
+func (l *LinkChecker) getWithRetries(link string, maxRetries int) (*http.Response, *requestTrace, int, error) {
+
+	retries := 0
+
+	for {
+		resp, trace, err := l.get(link)
+		if retries >= maxRetries || !retryable(resp.StatusCode, err) {
+			return resp, trace, retries, err
+		}
+
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		retries++
+		time.Sleep(l.retryDelay * time.Duration(retries))
+	}
+}
@@ -0,0 +1,145 @@
+package linkchecker_test
+
+import (
+	"linkchecker"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRetriesDownHost(t *testing.T) {
+	t.Parallel()
+
+	var heads, gets int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			atomic.AddInt32(&heads, 1)
+		} else {
+			atomic.AddInt32(&gets, 1)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithRetries(2, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []linkchecker.Result{
+		{
+			ResponseCode:  http.StatusServiceUnavailable,
+			Url:           ts.URL,
+			ReferringSite: ts.URL,
+			Status:        linkchecker.StatusDown,
+			Problem:       "Non OK response",
+			Retries:       2,
+		},
+	}
+
+	got := l.GetAllResults()
+
+	if !cmp.Equal(want, got, ignoreMetrics, ignoreReferrers) {
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics, ignoreReferrers))
+	}
+
+	// the get isn't retried after the head used up the retries
+	if atomic.LoadInt32(&heads) != 3 || atomic.LoadInt32(&gets) != 1 {
+		t.Fatalf("want 3 head and 1 get requests, got: %d and %d", atomic.LoadInt32(&heads), atomic.LoadInt32(&gets))
+	}
+
+}
+
+func TestRetriesHeadNotImplemented(t *testing.T) {
+	t.Parallel()
+
+	var heads int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			atomic.AddInt32(&heads, 1)
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		w.Write([]byte(`home`))
+	}))
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithRetries(2, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := l.GetAllResults(); len(got) != 0 {
+		t.Fatalf("want no problems, got: %v", got)
+	}
+
+	if atomic.LoadInt32(&heads) != 1 {
+		t.Fatalf("want head not retried after a 501, got: %d head requests", atomic.LoadInt32(&heads))
+	}
+
+}
+
+func TestRetriesUnknownError(t *testing.T) {
+	t.Parallel()
+
+	// answer every connection with something that isn't http
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	var connections int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&connections, 1)
+			conn.Write([]byte("nonsense\r\n\r\n"))
+			conn.Close()
+		}
+	}()
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithRetries(2, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = l.Check("http://" + listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := l.GetAllResults()
+	if len(got) != 1 || got[0].ErrorKind != linkchecker.ErrorUnknown || got[0].Retries != 0 {
+		t.Fatalf("want 1 unknown error that isn't retried, got: %v", got)
+	}
+
+	if atomic.LoadInt32(&connections) != 1 {
+		t.Fatalf("want 1 request, got: %d", atomic.LoadInt32(&connections))
+	}
+
+}
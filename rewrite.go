@@ -0,0 +1,74 @@
+package linkchecker
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RewriteRule maps a link to another url before it is fetched, either by
+// replacing Prefix or by replacing matches of Pattern
+type RewriteRule struct {
+	Prefix      string
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+func (r RewriteRule) Apply(link string) (string, bool) {
+
+	if r.Pattern != nil {
+		if !r.Pattern.MatchString(link) {
+			return link, false
+		}
+		return r.Pattern.ReplaceAllString(link, r.Replacement), true
+	}
+
+	if r.Prefix != "" && strings.HasPrefix(link, r.Prefix) {
+		return r.Replacement + strings.TrimPrefix(link, r.Prefix), true
+	}
+
+	return link, false
+}
+
+// WithRewriteRules adds rules that are tried in order, the first matching
+// rule rewrites the link
+func WithRewriteRules(rules ...RewriteRule) Option {
+	return func(l *LinkChecker) error {
+		l.rewriteRules = append(l.rewriteRules, rules...)
+		return nil
+	}
+}
+
+func (l *LinkChecker) Rewrite(link string) string {
+
+	for _, rule := range l.rewriteRules {
+		rewritten, ok := rule.Apply(link)
+		if ok {
+			return rewritten
+		}
+	}
+
+	return link
+}
+
+// ParseRewriteRule parses a from=to rule as given on the command line.
+// When isRegex is set, from is a regular expression and to may refer to
+// its capture groups.
+func ParseRewriteRule(value string, isRegex bool) (RewriteRule, error) {
+
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return RewriteRule{}, fmt.Errorf("invalid rewrite rule %q, expected from=to", value)
+	}
+
+	if !isRegex {
+		return RewriteRule{Prefix: parts[0], Replacement: parts[1]}, nil
+	}
+
+	pattern, err := regexp.Compile(parts[0])
+	if err != nil {
+		return RewriteRule{}, fmt.Errorf("invalid rewrite pattern %q, %s", parts[0], err)
+	}
+
+	return RewriteRule{Pattern: pattern, Replacement: parts[1]}, nil
+}
@@ -0,0 +1,151 @@
+package linkchecker_test
+
+import (
+	"linkchecker"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRewriteProductionLinksToPreview(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<a href="https://example.com/about">about</a>`))
+		case "/about":
+			w.Write([]byte(`<a href="https://example.com/missing">missing</a>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithVerboseMode(),
+		linkchecker.WithRewriteRules(linkchecker.RewriteRule{
+			Prefix:      "https://example.com",
+			Replacement: ts.URL,
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []linkchecker.Result{
+		{
+			ResponseCode:  http.StatusOK,
+			Url:           ts.URL,
+			ReferringSite: ts.URL,
+			Status:        linkchecker.StatusUp,
+		},
+		{
+			ResponseCode:  http.StatusOK,
+			Url:           ts.URL + "/about",
+			OriginalUrl:   "https://example.com/about",
+			ReferringSite: ts.URL,
+			Status:        linkchecker.StatusUp,
+		},
+		{
+			ResponseCode:  http.StatusNotFound,
+			Url:           ts.URL + "/missing",
+			OriginalUrl:   "https://example.com/missing",
+			ReferringSite: ts.URL + "/about",
+			Status:        linkchecker.StatusDown,
+			Problem:       "Non OK response",
+		},
+	}
+
+	got := l.GetAllResults()
+
+	if !cmp.Equal(want, got, ignoreMetrics, ignoreReferrers) {
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics, ignoreReferrers))
+	}
+
+}
+
+func TestRewrittenLinksCheckedForTraps(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.Write([]byte(`<a href="https://example.com/archive/1">1</a><a href="https://example.com/archive/1">1</a><a href="https://example.com/archive/2">2</a>`))
+		}
+	}))
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithConfigureRatelimiter(1000, 1000),
+		linkchecker.WithRewriteRules(linkchecker.RewriteRule{
+			Prefix:      "https://example.com",
+			Replacement: ts.URL,
+		}),
+		linkchecker.WithTrapDetection(linkchecker.TrapConfig{
+			PatternCaps: map[string]int{"/archive/": 1},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []linkchecker.Result{
+		{
+			Url:           ts.URL + "/archive/2",
+			OriginalUrl:   "https://example.com/archive/2",
+			ReferringSite: ts.URL,
+			Status:        linkchecker.StatusTrapped,
+			Problem:       "Crawler trap, more than 1 pages match /archive/",
+		},
+	}
+
+	got := l.GetAllResults()
+
+	if !cmp.Equal(want, got, ignoreMetrics, ignoreReferrers) {
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics, ignoreReferrers))
+	}
+
+}
+
+func TestParseRewriteRule(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		rule    string
+		isRegex bool
+		link    string
+		want    string
+	}
+
+	tcs := []testCase{
+		{rule: "https://example.com=https://pr-123.preview.example.net", link: "https://example.com/docs", want: "https://pr-123.preview.example.net/docs"},
+		{rule: `^https://(www\.)?example\.com/=https://preview.example.net/`, isRegex: true, link: "https://www.example.com/docs", want: "https://preview.example.net/docs"},
+		{rule: "https://example.com=https://preview.example.net", link: "https://other.com/docs", want: "https://other.com/docs"},
+	}
+
+	for _, tc := range tcs {
+		rule, err := linkchecker.ParseRewriteRule(tc.rule, tc.isRegex)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, _ := rule.Apply(tc.link)
+
+		if tc.want != got {
+			t.Fatalf("want: %s, got: %s", tc.want, got)
+		}
+	}
+
+}
@@ -0,0 +1,159 @@
+package linkchecker
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationUri string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifRules gives the rule and level used for each status, statuses
+// without a rule are not reported
+var sarifRules = map[Status]struct {
+	rule  sarifRule
+	level string
+}{
+	StatusDown:        {sarifRule{"broken-link", sarifMessage{"Link is broken"}}, "error"},
+	StatusSoft404:     {sarifRule{"soft-404", sarifMessage{"Link leads to a page that looks like a not found page"}}, "error"},
+	StatusTimeout:     {sarifRule{"link-timeout", sarifMessage{"Link timed out"}}, "warning"},
+	StatusRateLimited: {sarifRule{"link-rate-limited", sarifMessage{"Link could not be checked due to rate limiting"}}, "warning"},
+	Status999:         {sarifRule{"link-unverified", sarifMessage{"Link could not be verified"}}, "warning"},
+	StatusUp:          {sarifRule{"link-warning", sarifMessage{"Link works but has warnings"}}, "note"},
+}
+
+// NewSARIFReporter returns a reporter writing SARIF for code scanning,
+// with a result for each link and referring page.  Pages are mapped to
+// their source files by sources when given.
+func NewSARIFReporter(w io.Writer, sources SourceMap) Reporter {
+	return sarifReporter{w: w, sources: sources}
+}
+
+type sarifReporter struct {
+	w       io.Writer
+	sources SourceMap
+}
+
+func (r sarifReporter) Result(result Result) error {
+	return nil
+}
+
+// Finish writes the log once every referring page is known
+func (r sarifReporter) Finish(report Report) error {
+
+	locator := newSourceLocator(r.sources)
+
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "linkchecker",
+			InformationUri: "https://github.com/mbarley333/linkchecker",
+		}},
+		Results: []sarifResult{},
+	}
+
+	used := map[Status]bool{}
+
+	for _, result := range report.Results {
+
+		rule, ok := sarifRules[result.Status]
+		if !ok || !isFinding(result) {
+			continue
+		}
+		used[result.Status] = true
+
+		message := findingMessage(result)
+
+		for _, referrer := range result.AllReferrers() {
+
+			location := locator.locate(result, referrer)
+
+			physical := sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{Uri: location.Path},
+			}
+			if location.Line > 0 {
+				physical.Region = &sarifRegion{StartLine: location.Line, StartColumn: location.Column}
+			}
+
+			run.Results = append(run.Results, sarifResult{
+				RuleId:    rule.rule.Id,
+				Level:     rule.level,
+				Message:   sarifMessage{Text: message},
+				Locations: []sarifLocation{{PhysicalLocation: physical}},
+			})
+		}
+	}
+
+	// rules are listed in status order so the output is stable
+	statuses := []Status{}
+	for status := range used {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i] < statuses[j] })
+
+	run.Tool.Driver.Rules = []sarifRule{}
+	for _, status := range statuses {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRules[status].rule)
+	}
+
+	encoder := json.NewEncoder(r.w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
@@ -0,0 +1,118 @@
+package linkchecker_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"linkchecker"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSARIFReporter(t *testing.T) {
+	t.Parallel()
+
+	source := filepath.Join(t.TempDir(), "about.md")
+	err := os.WriteFile(source, []byte("# About\n\nRead the [guide](/guide) first.\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := linkchecker.Report{
+		Results: []linkchecker.Result{
+			{
+				ResponseCode:  http.StatusNotFound,
+				Url:           "https://example.com/guide",
+				ReferringSite: "https://example.com/about",
+				Problem:       "Non OK response",
+				Status:        linkchecker.StatusDown,
+				Referrers: []linkchecker.Referrer{
+					{Url: "https://example.com/about", Text: "guide", Line: 40, Column: 12},
+					{Url: "https://example.com/contact", Text: "guide", Line: 8, Column: 3},
+				},
+			},
+			{
+				ResponseCode:  http.StatusNotFound,
+				Url:           "https://example.com/missing",
+				ReferringSite: "https://example.com/about",
+				Problem:       "Non OK response",
+				Status:        linkchecker.StatusDown,
+				Referrers: []linkchecker.Referrer{
+					{Url: "https://example.com/about", Text: "it", Line: 12, Column: 5},
+				},
+			},
+			{
+				ResponseCode:  http.StatusTooManyRequests,
+				Url:           "https://other.example.com",
+				ReferringSite: "https://example.com/contact",
+				Problem:       "Site rate limit exceeded",
+				Status:        linkchecker.StatusRateLimited,
+			},
+		},
+	}
+
+	var output bytes.Buffer
+
+	reporter := linkchecker.NewSARIFReporter(&output, linkchecker.SourceMap{{Prefix: "/about", Source: source}})
+
+	err = reporter.Finish(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Version string
+		Runs    []struct {
+			Results []struct {
+				RuleId    string
+				Level     string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							Uri string
+						}
+						Region struct {
+							StartLine   int
+							StartColumn int
+						}
+					}
+				}
+			}
+		}
+	}
+
+	err = json.Unmarshal(output.Bytes(), &log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("want a single sarif 2.1.0 run, got: %s", output.String())
+	}
+
+	type location struct {
+		RuleId, Level, Uri string
+		Line, Column       int
+	}
+
+	got := []location{}
+	for _, result := range log.Runs[0].Results {
+		physical := result.Locations[0].PhysicalLocation
+		got = append(got, location{result.RuleId, result.Level, physical.ArtifactLocation.Uri, physical.Region.StartLine, physical.Region.StartColumn})
+	}
+
+	want := []location{
+		{"broken-link", "error", source, 3, 18},
+		{"broken-link", "error", "https://example.com/contact", 8, 3},
+		{"broken-link", "error", "https://example.com/about", 12, 5},
+		{"link-rate-limited", "warning", "https://example.com/contact", 0, 0},
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Fatal(cmp.Diff(want, got))
+	}
+
+}
@@ -0,0 +1,122 @@
+package linkchecker
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// StaticServer serves a local build directory on a loopback address so
+// it can be crawled with the same semantics as the production site
+type StaticServer struct {
+	URL string
+
+	dir          string
+	cleanURLs    bool
+	notFoundPage string
+	files        http.Handler
+	server       *http.Server
+}
+
+type OptionStatic func(*StaticServer) error
+
+// WithCleanURLs serves /about from about.html when there is no about
+// file or directory
+func WithCleanURLs() OptionStatic {
+	return func(s *StaticServer) error {
+		s.cleanURLs = true
+		return nil
+	}
+}
+
+// WithNotFoundPage serves the given file, relative to the directory,
+// with a 404 status for missing pages
+func WithNotFoundPage(name string) OptionStatic {
+	return func(s *StaticServer) error {
+		s.notFoundPage = name
+		return nil
+	}
+}
+
+func NewStaticServer(dir string, opts ...OptionStatic) (*StaticServer, error) {
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	s := &StaticServer{
+		dir:   dir,
+		files: http.FileServer(http.Dir(dir)),
+	}
+
+	for _, o := range opts {
+		err := o(s)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("unable to start static server, %s", err)
+	}
+
+	s.URL = "http://" + listener.Addr().String()
+	s.server = &http.Server{Handler: s}
+
+	go s.server.Serve(listener)
+
+	return s, nil
+}
+
+func (s *StaticServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	name := filepath.Join(s.dir, filepath.FromSlash(path.Clean("/"+r.URL.Path)))
+
+	info, err := os.Stat(name)
+	if err == nil && !info.IsDir() {
+		s.files.ServeHTTP(w, r)
+		return
+	}
+
+	// like a static host, directories are only served by their index
+	// page rather than listed
+	if err == nil {
+		_, err = os.Stat(filepath.Join(name, "index.html"))
+		if err == nil {
+			s.files.ServeHTTP(w, r)
+			return
+		}
+	}
+
+	if s.cleanURLs {
+		info, err := os.Stat(name + ".html")
+		if err == nil && !info.IsDir() {
+			http.ServeFile(w, r, name+".html")
+			return
+		}
+	}
+
+	if s.notFoundPage != "" {
+		data, err := os.ReadFile(filepath.Join(s.dir, s.notFoundPage))
+		if err == nil {
+			w.Header().Set("content-type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write(data)
+			return
+		}
+	}
+
+	http.NotFound(w, r)
+}
+
+func (s *StaticServer) Close() error {
+	return s.server.Close()
+}
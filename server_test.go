@@ -0,0 +1,131 @@
+package linkchecker_test
+
+import (
+	"io"
+	"linkchecker"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStaticServer(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"index.html": `<a href="/about">about</a><a href="/missing">missing</a>`,
+		"about.html": `about`,
+		"404.html":   `not here`,
+	}
+
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server, err := linkchecker.NewStaticServer(dir,
+		linkchecker.WithCleanURLs(),
+		linkchecker.WithNotFoundPage("404.html"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusNotFound || string(body) != "not here" {
+		t.Fatalf("want custom 404 page, got: %d %q", resp.StatusCode, body)
+	}
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithVerboseMode(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = l.Check(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []linkchecker.Result{
+		{ResponseCode: http.StatusOK, Url: server.URL, ReferringSite: server.URL, Status: linkchecker.StatusUp},
+		{ResponseCode: http.StatusOK, Url: server.URL + "/about", ReferringSite: server.URL, Status: linkchecker.StatusUp},
+		{ResponseCode: http.StatusNotFound, Url: server.URL + "/missing", ReferringSite: server.URL, Status: linkchecker.StatusDown, Problem: "Non OK response"},
+	}
+
+	got := l.GetAllResults()
+
+	if !cmp.Equal(want, got, ignoreMetrics, ignoreReferrers) {
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics, ignoreReferrers))
+	}
+
+}
+
+func TestStaticServerDirectories(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"docs/index.html": `docs`,
+		"assets/logo.svg": `<svg></svg>`,
+	}
+
+	for name, content := range files {
+		err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server, err := linkchecker.NewStaticServer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	tcs := []struct {
+		path string
+		want int
+	}{
+		{path: "/docs/", want: http.StatusOK},
+		{path: "/docs", want: http.StatusOK},
+		{path: "/assets/logo.svg", want: http.StatusOK},
+		{path: "/assets/", want: http.StatusNotFound},
+		{path: "/", want: http.StatusNotFound},
+	}
+
+	for _, tc := range tcs {
+		resp, err := http.Get(server.URL + tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.want {
+			t.Fatalf("want %d for %s, got: %d", tc.want, tc.path, resp.StatusCode)
+		}
+	}
+
+}
@@ -0,0 +1,145 @@
+package linkchecker
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+)
+
+// soft404Similarity is how alike a page and the probe of a nonexistent
+// page on the same host must be for the page to count as a soft 404
+const soft404Similarity = 0.9
+
+// soft404MaxBody is the most of an external page that is read to check
+// whether it is a soft 404
+const soft404MaxBody = 5 << 20
+
+// soft404Pattern matches a title or heading that is only a not found
+// message, so pages about 404 errors, such as "Handling 404 errors",
+// are not reported
+var soft404Pattern = regexp.MustCompile(`(?i)^(error\s+)?(404|(404\s*[-:]?\s*)?((the|this)\s+)?((page|file|document|resource)\s+)?((was|is)\s+)?(not found|doesn't exist|does not exist|no longer exists|no longer available|can(no|')t be found))[.!]*$`)
+
+// soft404Separator splits the site name from a title such as
+// "Page Not Found | Example"
+var soft404Separator = regexp.MustCompile(`\s+[|\-–—:·]\s+`)
+
+// soft404Probe holds the words of the page a host returns for a url that
+// does not exist, or nil if the host correctly returns an error
+type soft404Probe struct {
+	once  sync.Once
+	words map[string]bool
+}
+
+func WithSoft404Detection() Option {
+	return func(l *LinkChecker) error {
+		l.soft404 = true
+		l.soft404Probes = make(map[string]*soft404Probe)
+		return nil
+	}
+}
+
+// detectSoft404 reports whether a page returned with a 200 is really a
+// not found page, comparing it to a probe of a nonexistent url on the
+// same host and checking its title and headings
+func (l *LinkChecker) detectSoft404(u *url.URL, body []byte) (string, bool) {
+
+	doc, err := htmlquery.Parse(bytes.NewReader(body))
+	if err != nil {
+		return "", false
+	}
+
+	for _, heading := range htmlquery.Find(doc, "//title | //h1") {
+		text := strings.TrimSpace(htmlquery.InnerText(heading))
+		for _, part := range soft404Separator.Split(text, -1) {
+			if soft404Pattern.MatchString(strings.TrimSpace(part)) {
+				return fmt.Sprintf("Page looks like a not found page, %q", text), true
+			}
+		}
+	}
+
+	probe := l.soft404ProbeFor(u)
+	if probe.words == nil {
+		return "", false
+	}
+
+	if similarity(probe.words, pageWords(body)) >= soft404Similarity {
+		return "Page matches the response for a nonexistent url on the same host", true
+	}
+
+	return "", false
+}
+
+func (l *LinkChecker) soft404ProbeFor(u *url.URL) *soft404Probe {
+
+	l.soft404Mutex.Lock()
+	probe, ok := l.soft404Probes[u.Host]
+	if !ok {
+		probe = &soft404Probe{}
+		l.soft404Probes[u.Host] = probe
+	}
+	l.soft404Mutex.Unlock()
+
+	probe.once.Do(func() {
+		link := fmt.Sprintf("%s://%s/linkchecker-soft404-probe-%d", u.Scheme, u.Host, time.Now().UnixNano())
+
+		resp, err := l.GetResponse(link)
+		if err != nil {
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(resp.Body, soft404MaxBody))
+		if err != nil {
+			return
+		}
+
+		probe.words = pageWords(body)
+	})
+
+	return probe
+}
+
+func pageWords(body []byte) map[string]bool {
+
+	words := make(map[string]bool)
+
+	doc, err := htmlquery.Parse(bytes.NewReader(body))
+	if err != nil {
+		return words
+	}
+
+	for _, word := range strings.Fields(strings.ToLower(htmlquery.InnerText(doc))) {
+		words[word] = true
+	}
+
+	return words
+}
+
+// similarity is the jaccard index of two sets of words
+func similarity(a, b map[string]bool) float64 {
+
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
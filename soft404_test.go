@@ -0,0 +1,67 @@
+package linkchecker_test
+
+import (
+	"linkchecker"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSoft404Detection(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<h1>Welcome</h1><a href="about">about</a><a href="retired">retired</a><a href="moved">moved</a><a href="errors">errors</a>`))
+		case "/about":
+			w.Write([]byte(`<h1>About us</h1><p>We build link checkers in Hawaii.</p>`))
+		case "/errors":
+			w.Write([]byte(`<html><head><title>Handling 404 errors | Example</title></head><body><h1>Handling 404 errors</h1><p>Return a real 404 for pages that are not found.</p></body></html>`))
+		case "/moved":
+			w.Write([]byte(`<html><head><title>Page Not Found | Example</title></head></html>`))
+		default:
+			// the cms returns 200 for anything it doesn't know about
+			w.Write([]byte(`<h2>Sorry, we looked everywhere but there is nothing here</h2>`))
+		}
+	}))
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithSoft404Detection(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []linkchecker.Result{
+		{
+			ResponseCode:  http.StatusOK,
+			Url:           ts.URL + "/moved",
+			ReferringSite: ts.URL,
+			Status:        linkchecker.StatusSoft404,
+			Problem:       `Page looks like a not found page, "Page Not Found | Example"`,
+		},
+		{
+			ResponseCode:  http.StatusOK,
+			Url:           ts.URL + "/retired",
+			ReferringSite: ts.URL,
+			Status:        linkchecker.StatusSoft404,
+			Problem:       "Page matches the response for a nonexistent url on the same host",
+		},
+	}
+
+	got := l.GetAllResults()
+
+	if !cmp.Equal(want, got, ignoreMetrics, ignoreReferrers) {
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics, ignoreReferrers))
+	}
+
+}
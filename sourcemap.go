@@ -0,0 +1,149 @@
+package linkchecker
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// SourceMapping maps pages whose url path starts with Prefix to the file
+// they are built from.  {path} in Source is replaced by the rest of the
+// url path, or index for the prefix itself, so /docs/ mapped to
+// content/docs/{path}.md maps /docs/setup to content/docs/setup.md
+type SourceMapping struct {
+	Prefix string
+	Source string
+}
+
+// SourceMap is tried in order, the first matching mapping gives the
+// source file of a page
+type SourceMap []SourceMapping
+
+// ParseSourceMapping parses a prefix=source mapping as given on the
+// command line
+func ParseSourceMapping(value string) (SourceMapping, error) {
+
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return SourceMapping{}, fmt.Errorf("invalid source mapping %q, expected prefix=source", value)
+	}
+
+	return SourceMapping{Prefix: parts[0], Source: parts[1]}, nil
+}
+
+// Source returns the source file for a page
+func (m SourceMap) Source(page string) (string, bool) {
+
+	u, err := url.Parse(page)
+	if err != nil {
+		return "", false
+	}
+
+	urlPath := u.Path
+	if urlPath == "" {
+		urlPath = "/"
+	}
+
+	for _, mapping := range m {
+		if !strings.HasPrefix(urlPath, mapping.Prefix) {
+			continue
+		}
+
+		rest := strings.Trim(strings.TrimPrefix(urlPath, mapping.Prefix), "/")
+		rest = strings.TrimSuffix(rest, ".html")
+		if rest == "" {
+			rest = "index"
+		}
+
+		return strings.ReplaceAll(mapping.Source, "{path}", rest), true
+	}
+
+	return "", false
+}
+
+// sourceLocation is where a link appears, in the source file of the
+// page when it is mapped and otherwise in the page itself
+type sourceLocation struct {
+	Path   string
+	Line   int
+	Column int
+}
+
+// sourceLocator finds links in source files, reading each file once
+type sourceLocator struct {
+	sources SourceMap
+	files   map[string][]string
+}
+
+func newSourceLocator(sources SourceMap) *sourceLocator {
+	return &sourceLocator{
+		sources: sources,
+		files:   make(map[string][]string),
+	}
+}
+
+// locate returns where the link to result appears on the referring
+// page.  Source files are searched for the link, as the position in
+// the built page rarely matches the source.  When the link can't be
+// found in the source, the page and the position found while parsing
+// it are used, rather than pointing at the wrong line of the source.
+func (s *sourceLocator) locate(result Result, referrer Referrer) sourceLocation {
+
+	location := sourceLocation{
+		Path:   referrer.Url,
+		Line:   referrer.Line,
+		Column: referrer.Column,
+	}
+
+	source, ok := s.sources.Source(referrer.Url)
+	if !ok {
+		return location
+	}
+
+	lines, ok := s.files[source]
+	if !ok {
+		data, err := os.ReadFile(source)
+		if err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		s.files[source] = lines
+	}
+
+	needles := []string{result.Url}
+	if result.OriginalUrl != "" {
+		needles = append(needles, result.OriginalUrl)
+	}
+	u, err := url.Parse(result.Url)
+	if err == nil && u.Path != "" && u.Path != "/" {
+		needles = append(needles, u.Path)
+
+		// root relative to a mapped prefix, such as the directory of a
+		// local build
+		for _, mapping := range s.sources {
+			rest := strings.TrimPrefix(u.Path, strings.TrimSuffix(mapping.Prefix, "/"))
+			if rest != u.Path && strings.HasPrefix(rest, "/") && rest != "/" {
+				needles = append(needles, rest)
+				break
+			}
+		}
+	}
+	if len(referrer.Text) >= 3 {
+		needles = append(needles, referrer.Text)
+	}
+
+	for _, needle := range needles {
+		for i, line := range lines {
+			column := strings.Index(line, needle)
+			if column >= 0 {
+				return sourceLocation{
+					Path:   source,
+					Line:   i + 1,
+					Column: column + 1,
+				}
+			}
+		}
+	}
+
+	return location
+}
@@ -0,0 +1,43 @@
+package linkchecker_test
+
+import (
+	"linkchecker"
+	"testing"
+)
+
+func TestSourceMap(t *testing.T) {
+	t.Parallel()
+
+	sources := linkchecker.SourceMap{}
+	for _, value := range []string{"/docs/=content/docs/{path}.md", "/=content/{path}.md"} {
+		mapping, err := linkchecker.ParseSourceMapping(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources = append(sources, mapping)
+	}
+
+	tcs := []struct {
+		page string
+		want string
+	}{
+		{page: "https://example.com", want: "content/index.md"},
+		{page: "https://example.com/about", want: "content/about.md"},
+		{page: "https://example.com/docs/", want: "content/docs/index.md"},
+		{page: "https://example.com/docs/setup.html", want: "content/docs/setup.md"},
+		{page: "https://example.com/docs/guides/install/", want: "content/docs/guides/install.md"},
+	}
+
+	for _, tc := range tcs {
+		got, ok := sources.Source(tc.page)
+		if !ok || got != tc.want {
+			t.Fatalf("want %s for %s, got: %s", tc.want, tc.page, got)
+		}
+	}
+
+	_, err := linkchecker.ParseSourceMapping("content/{path}.md")
+	if err == nil {
+		t.Fatal("want error for mapping without a prefix")
+	}
+
+}
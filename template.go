@@ -0,0 +1,147 @@
+package linkchecker
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Templates lay out the results written to the output.  Result is
+// rendered with each Result as it arrives, on its own line.  Header is
+// rendered with a Report holding the metadata and summary as the crawl
+// starts, and Footer with the complete Report once it is done.  Any of
+// them may be left empty.
+type Templates struct {
+	Header string
+	Result string
+	Footer string
+}
+
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"round": func(d time.Duration) time.Duration {
+		return d.Round(time.Millisecond)
+	},
+}
+
+// resultTemplates renders results as they are reported, keeping them
+// for the footer.  The first error rendering a result is returned by
+// Check, as field errors are only found when a template is executed.
+type resultTemplates struct {
+	header *template.Template
+	result *template.Template
+	footer *template.Template
+
+	mutex   sync.Mutex
+	results []Result
+	err     error
+}
+
+// WithTemplate writes results to the output using text/template
+// templates in place of Result.String
+func WithTemplate(templates Templates) Option {
+	return func(l *LinkChecker) error {
+
+		t := &resultTemplates{}
+		var err error
+
+		t.header, err = parseTemplate("header", templates.Header)
+		if err != nil {
+			return err
+		}
+
+		result := templates.Result
+		if result != "" && !strings.HasSuffix(result, "\n") {
+			result += "\n"
+		}
+		t.result, err = parseTemplate("result", result)
+		if err != nil {
+			return err
+		}
+
+		t.footer, err = parseTemplate("footer", templates.Footer)
+		if err != nil {
+			return err
+		}
+
+		l.templates = t
+		return nil
+	}
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+
+	if text == "" {
+		return nil, nil
+	}
+
+	t, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template, %s", name, err)
+	}
+
+	return t, nil
+}
+
+func (l *LinkChecker) renderHeader() error {
+
+	if l.templates.header == nil {
+		return nil
+	}
+
+	return l.templates.header.Execute(l.output, l.Report(nil))
+}
+
+func (l *LinkChecker) renderResult(result Result) {
+
+	l.templates.mutex.Lock()
+	defer l.templates.mutex.Unlock()
+
+	l.templates.results = append(l.templates.results, result)
+
+	if l.templates.result == nil || l.templates.err != nil {
+		return
+	}
+
+	err := l.templates.result.Execute(l.output, result)
+	if err != nil {
+		l.templates.err = fmt.Errorf("unable to render result template, %s", err)
+	}
+}
+
+// renderError returns the first error rendering a result
+func (l *LinkChecker) renderError() error {
+
+	l.templates.mutex.Lock()
+	defer l.templates.mutex.Unlock()
+
+	return l.templates.err
+}
+
+func (l *LinkChecker) renderFooter() error {
+
+	if l.templates.footer == nil {
+		return nil
+	}
+
+	l.templates.mutex.Lock()
+	results := make([]Result, len(l.templates.results))
+	copy(results, l.templates.results)
+	l.templates.mutex.Unlock()
+
+	for i := range results {
+		results[i].Referrers = l.Referrers(results[i].Url)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Url < results[j].Url })
+
+	return l.templates.footer.Execute(l.output, l.Report(results))
+}
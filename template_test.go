@@ -0,0 +1,76 @@
+package linkchecker_test
+
+import (
+	"bytes"
+	"linkchecker"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWithTemplate(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`<a href="/missing">missing</a>`))
+	}))
+
+	var output bytes.Buffer
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithOutput(&output),
+		linkchecker.WithTemplate(linkchecker.Templates{
+			Header: "checking {{.Summary.Site}}\n",
+			Result: "{{.Status}} {{.ResponseCode}} {{.Url}}",
+			Footer: "{{.Summary.Total}} checked{{range .Results}}, {{.Url}} linked from {{(index .Referrers 0).Text}}{{end}}\n",
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.GetAllResults()
+
+	want := "checking " + ts.URL + "\n" +
+		"Down 404 " + ts.URL + "/missing\n" +
+		"2 checked, " + ts.URL + "/missing linked from missing\n"
+
+	if !cmp.Equal(want, output.String()) {
+		t.Fatal(cmp.Diff(want, output.String()))
+	}
+
+	_, err = linkchecker.NewLinkChecker(
+		linkchecker.WithTemplate(linkchecker.Templates{Result: "{{.Url"}),
+	)
+	if err == nil {
+		t.Fatal("want error for invalid template")
+	}
+
+	// unknown fields are only found when the template is executed
+	l, err = linkchecker.NewLinkChecker(
+		linkchecker.WithOutput(&output),
+		linkchecker.WithTemplate(linkchecker.Templates{Result: "{{.Nope}}"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err == nil {
+		t.Fatal("want error for template with an unknown field")
+	}
+
+}
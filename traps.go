@@ -0,0 +1,135 @@
+package linkchecker
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// TrapConfig sets the heuristics used to spot crawler traps such as
+// endless calendars and faceted search pages.  A zero value disables
+// that heuristic.
+type TrapConfig struct {
+	// times a single path segment may appear in a url
+	MaxRepeatedSegments int
+	// distinct query strings crawled for a single path
+	MaxQueryVariants int
+	MaxURLLength     int
+	// regular expressions mapped to the number of urls they may match
+	PatternCaps map[string]int
+}
+
+var DefaultTrapConfig = TrapConfig{
+	MaxRepeatedSegments: 3,
+	MaxQueryVariants:    50,
+	MaxURLLength:        2000,
+}
+
+type trapDetector struct {
+	config   TrapConfig
+	patterns []patternCap
+
+	mutex         sync.Mutex
+	queryVariants map[string]map[string]bool
+	seen          map[string]bool
+}
+
+type patternCap struct {
+	pattern *regexp.Regexp
+	max     int
+	count   int
+}
+
+func WithTrapDetection(config TrapConfig) Option {
+	return func(l *LinkChecker) error {
+		traps := &trapDetector{
+			config:        config,
+			queryVariants: make(map[string]map[string]bool),
+			seen:          make(map[string]bool),
+		}
+
+		for pattern, max := range config.PatternCaps {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("invalid trap pattern %q, %s", pattern, err)
+			}
+			traps.patterns = append(traps.patterns, patternCap{pattern: re, max: max})
+		}
+
+		l.traps = traps
+		return nil
+	}
+}
+
+// check reports whether link looks like part of a crawler trap.  Links
+// that pass are counted towards the query and pattern limits once, however
+// often they are linked.
+func (t *trapDetector) check(link string) (string, bool) {
+
+	if t.config.MaxURLLength > 0 && len(link) > t.config.MaxURLLength {
+		return fmt.Sprintf("Crawler trap, url is longer than %d characters", t.config.MaxURLLength), true
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", false
+	}
+
+	if t.config.MaxRepeatedSegments > 0 {
+		segments := make(map[string]int)
+		for _, segment := range strings.Split(u.Path, "/") {
+			if segment == "" {
+				continue
+			}
+			segments[segment]++
+			if segments[segment] > t.config.MaxRepeatedSegments {
+				return fmt.Sprintf("Crawler trap, path segment %q repeats more than %d times", segment, t.config.MaxRepeatedSegments), true
+			}
+		}
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.seen[link] {
+		return "", false
+	}
+
+	// every limit is checked before any is counted, so a trapped link
+	// doesn't use up the others
+	countQuery := t.config.MaxQueryVariants > 0 && u.RawQuery != ""
+	path := u.Scheme + "://" + u.Host + u.Path
+	if countQuery {
+		variants := t.queryVariants[path]
+		if !variants[u.RawQuery] && len(variants) >= t.config.MaxQueryVariants {
+			return fmt.Sprintf("Crawler trap, more than %d query variations of %s", t.config.MaxQueryVariants, path), true
+		}
+	}
+
+	matched := []int{}
+	for i := range t.patterns {
+		if !t.patterns[i].pattern.MatchString(link) {
+			continue
+		}
+		if t.patterns[i].count >= t.patterns[i].max {
+			return fmt.Sprintf("Crawler trap, more than %d pages match %s", t.patterns[i].max, t.patterns[i].pattern), true
+		}
+		matched = append(matched, i)
+	}
+
+	if countQuery {
+		if t.queryVariants[path] == nil {
+			t.queryVariants[path] = make(map[string]bool)
+		}
+		t.queryVariants[path][u.RawQuery] = true
+	}
+	for _, i := range matched {
+		t.patterns[i].count++
+	}
+
+	t.seen[link] = true
+
+	return "", false
+}
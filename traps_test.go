@@ -0,0 +1,156 @@
+package linkchecker_test
+
+import (
+	"fmt"
+	"linkchecker"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCrawlerTrapDetection(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<a href="events?month=1">events</a><a href="/tag/tag/tag/tag/go">tags</a><a href="archive/1">archive</a>`))
+		case "/events":
+			// every month links to the next one, forever
+			month, _ := strconv.Atoi(r.URL.Query().Get("month"))
+			fmt.Fprintf(w, `<a href="events?month=%d">next month</a>`, month+1)
+		default:
+			var page int
+			fmt.Sscanf(r.URL.Path, "/archive/%d", &page)
+			fmt.Fprintf(w, `<a href="archive/%d">older</a>`, page+1)
+		}
+	}))
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithConfigureRatelimiter(1000, 1000),
+		linkchecker.WithTrapDetection(linkchecker.TrapConfig{
+			MaxRepeatedSegments: 3,
+			MaxQueryVariants:    5,
+			PatternCaps:         map[string]int{"/archive/": 3},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		ts.URL + "/archive/4":          "Crawler trap, more than 3 pages match /archive/",
+		ts.URL + "/events?month=6":     "Crawler trap, more than 5 query variations of " + ts.URL + "/events",
+		ts.URL + "/tag/tag/tag/tag/go": `Crawler trap, path segment "tag" repeats more than 3 times`,
+	}
+
+	got := l.GetAllResults()
+
+	if len(got) != len(want) {
+		t.Fatalf("want %d trapped results, got: %v", len(want), got)
+	}
+
+	for _, result := range got {
+		if result.Status != linkchecker.StatusTrapped || want[result.Url] != result.Problem {
+			t.Fatalf("want %s trapped with %q, got: %s %q", result.Url, want[result.Url], result.Status, result.Problem)
+		}
+	}
+
+}
+
+func TestCrawlerTrapRepeatedLinks(t *testing.T) {
+	t.Parallel()
+
+	// the same links in a header and a footer count once towards the cap
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.Write([]byte(`<a href="/archive/1">1</a><a href="/archive/2">2</a><p>content</p><a href="/archive/1">1</a><a href="/archive/2">2</a>`))
+		}
+	}))
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithVerboseMode(),
+		linkchecker.WithConfigureRatelimiter(1000, 1000),
+		linkchecker.WithTrapDetection(linkchecker.TrapConfig{
+			PatternCaps: map[string]int{"/archive/": 2},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := l.GetAllResults()
+
+	if len(got) != 3 {
+		t.Fatalf("want 3 results, got: %v", got)
+	}
+
+	for _, result := range got {
+		if result.Status != linkchecker.StatusUp {
+			t.Fatalf("want %s up, got: %s %q", result.Url, result.Status, result.Problem)
+		}
+	}
+
+}
+
+func TestCrawlerTrapRejectedLinksNotCounted(t *testing.T) {
+	t.Parallel()
+
+	// the second link is over the pattern cap, so it doesn't use up a
+	// query variation of /list
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.Write([]byte(`<a href="/list?page=1">1</a><a href="/list?page=2">2</a><a href="/list?sort=name">by name</a>`))
+		}
+	}))
+
+	l, err := linkchecker.NewLinkChecker(
+		linkchecker.WithVerboseMode(),
+		linkchecker.WithConfigureRatelimiter(1000, 1000),
+		linkchecker.WithTrapDetection(linkchecker.TrapConfig{
+			MaxQueryVariants: 2,
+			PatternCaps:      map[string]int{"page=": 1},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.HTTPClient = ts.Client()
+
+	err = l.Check(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]linkchecker.Status{
+		ts.URL:                     linkchecker.StatusUp,
+		ts.URL + "/list?page=1":    linkchecker.StatusUp,
+		ts.URL + "/list?page=2":    linkchecker.StatusTrapped,
+		ts.URL + "/list?sort=name": linkchecker.StatusUp,
+	}
+
+	got := map[string]linkchecker.Status{}
+	for _, result := range l.GetAllResults() {
+		got[result.Url] = result.Status
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Fatal(cmp.Diff(want, got))
+	}
+
+}
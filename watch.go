@@ -0,0 +1,414 @@
+package linkchecker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Watcher keeps the results of checking a local build directory in
+// memory, re-checking only the pages whose files change and the pages
+// linking to them
+type Watcher struct {
+	dir        string
+	site       string
+	home       string
+	root       string
+	opts       []Option
+	output     io.Writer
+	reportable func(Result) bool
+
+	results   map[string]Result
+	referrers map[string][]Referrer
+	files     map[string]string
+	modTimes  map[string]time.Time
+}
+
+func NewWatcher(dir string, opts ...Option) (*Watcher, error) {
+
+	site, err := FileUrl(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// the options are checked up front and give the output and filter
+	l, err := NewLinkChecker(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	home, err := l.setSite(site)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Watcher{
+		dir:        dir,
+		site:       site,
+		home:       home,
+		root:       l.fileRoot,
+		opts:       opts,
+		output:     l.output,
+		reportable: l.isReportable,
+		results:    make(map[string]Result),
+		referrers:  make(map[string][]Referrer),
+		files:      make(map[string]string),
+		modTimes:   make(map[string]time.Time),
+	}, nil
+}
+
+// Check crawls the whole directory, replacing any earlier results
+func (w *Watcher) Check() error {
+
+	modTimes, err := w.scan()
+	if err != nil {
+		return err
+	}
+	w.modTimes = modTimes
+
+	w.results = make(map[string]Result)
+	w.referrers = make(map[string][]Referrer)
+	w.files = make(map[string]string)
+
+	return w.check(nil)
+}
+
+// Poll re-checks the pages whose files have changed since the last
+// check, along with the pages linking to them, and returns the pages
+// that were checked
+func (w *Watcher) Poll() ([]string, error) {
+
+	modTimes, err := w.scan()
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make(map[string]bool)
+	for name, modTime := range modTimes {
+		previous, ok := w.modTimes[name]
+		if !ok || !previous.Equal(modTime) {
+			changed[name] = true
+		}
+	}
+	for name := range w.modTimes {
+		if _, ok := modTimes[name]; !ok {
+			changed[name] = true
+		}
+	}
+
+	w.modTimes = modTimes
+
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	pages := make(map[string]string)
+	for link, name := range w.files {
+
+		// a page may now be served from a different file, or from a
+		// file that has just been created
+		current := w.resolve(link)
+		if current == name && !changed[name] {
+			continue
+		}
+
+		pages[link] = w.results[link].ReferringSite
+		for _, referrer := range w.referrers[link] {
+			if _, ok := pages[referrer.Url]; !ok {
+				pages[referrer.Url] = w.results[referrer.Url].ReferringSite
+			}
+		}
+	}
+
+	if len(pages) == 0 {
+		return nil, nil
+	}
+
+	err = w.check(pages)
+	if err != nil {
+		return nil, err
+	}
+
+	checked := []string{}
+	for page := range pages {
+		checked = append(checked, page)
+	}
+	sort.Strings(checked)
+
+	return checked, nil
+}
+
+// Run checks the directory and then polls it for changes at each
+// interval, printing the broken links after every check until the
+// context is cancelled
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) error {
+
+	err := w.Check()
+	if err != nil {
+		return err
+	}
+	w.print(nil)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			checked, err := w.Poll()
+			if err != nil {
+				return err
+			}
+			if len(checked) > 0 {
+				w.print(checked)
+			}
+		}
+	}
+}
+
+// Results returns the current results, filtered as they would be by
+// Check
+func (w *Watcher) Results() []Result {
+
+	results := []Result{}
+	for _, result := range w.results {
+		if w.reportable(result) {
+			result.Referrers = w.Referrers(result.Url)
+			results = append(results, result)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Url < results[j].Url })
+
+	return results
+}
+
+// Referrers returns every page currently linking to target
+func (w *Watcher) Referrers(target string) []Referrer {
+
+	if len(w.referrers[target]) == 0 {
+		return nil
+	}
+
+	referrers := make([]Referrer, len(w.referrers[target]))
+	copy(referrers, w.referrers[target])
+
+	sort.Slice(referrers, func(i, j int) bool {
+		if referrers[i].Url != referrers[j].Url {
+			return referrers[i].Url < referrers[j].Url
+		}
+		if referrers[i].Line != referrers[j].Line {
+			return referrers[i].Line < referrers[j].Line
+		}
+		return referrers[i].Column < referrers[j].Column
+	})
+
+	return referrers
+}
+
+func (w *Watcher) print(checked []string) {
+
+	results := w.Results()
+
+	if checked == nil {
+		fmt.Fprintf(w.output, "%s checked, %d results\n", w.dir, len(results))
+	} else {
+		fmt.Fprintf(w.output, "%s re-checked %d pages, %d results\n", time.Now().Format("15:04:05"), len(checked), len(results))
+	}
+
+	for _, result := range results {
+		fmt.Fprintln(w.output, result)
+	}
+}
+
+// check crawls the whole site when pages is nil, otherwise only the
+// given pages and any links on them that have not been seen before
+func (w *Watcher) check(pages map[string]string) error {
+
+	l, err := NewLinkChecker(append(w.opts, WithVerboseMode())...)
+	if err != nil {
+		return err
+	}
+
+	// buffered so the results are dropped rather than blocking forever
+	// when the check fails
+	all := make(chan []Result, 1)
+	go func() {
+		all <- l.GetAllResults()
+	}()
+
+	if pages == nil {
+		err = l.Check(w.site)
+	} else {
+		crawled := []string{}
+		for link := range w.results {
+			if _, ok := pages[link]; !ok {
+				crawled = append(crawled, link)
+			}
+		}
+		err = l.recheck(w.site, pages, crawled)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	results := <-all
+
+	// links from the checked pages are replaced by the ones found now
+	for target, referrers := range w.referrers {
+		kept := []Referrer{}
+		for _, referrer := range referrers {
+			if _, ok := pages[referrer.Url]; !ok {
+				kept = append(kept, referrer)
+			}
+		}
+		w.referrers[target] = kept
+	}
+
+	l.referrerMutex.Lock()
+	for target, referrers := range l.referrers {
+		w.referrers[target] = append(w.referrers[target], referrers...)
+	}
+	l.referrerMutex.Unlock()
+
+	for _, result := range results {
+		result.Referrers = nil
+		w.results[result.Url] = result
+		if w.isInternal(result.Url) {
+			w.files[result.Url] = w.resolve(result.Url)
+		}
+	}
+
+	w.prune()
+
+	return nil
+}
+
+// prune drops results for links that are no longer linked from any page
+func (w *Watcher) prune() {
+
+	for {
+		pruned := false
+		for link := range w.results {
+			if link == w.home || len(w.referrers[link]) > 0 {
+				continue
+			}
+
+			delete(w.results, link)
+			delete(w.files, link)
+			delete(w.referrers, link)
+
+			for target, referrers := range w.referrers {
+				kept := []Referrer{}
+				for _, referrer := range referrers {
+					if referrer.Url != link {
+						kept = append(kept, referrer)
+					}
+				}
+				w.referrers[target] = kept
+			}
+
+			pruned = true
+		}
+
+		if !pruned {
+			return
+		}
+	}
+}
+
+func (w *Watcher) isInternal(link string) bool {
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+
+	return u.Scheme == "file"
+}
+
+// resolve returns the file a link is served from, or an empty string
+// for a missing page
+func (w *Watcher) resolve(link string) string {
+
+	u, err := url.Parse(link)
+	if err != nil || !strings.HasPrefix(u.Path, w.root) {
+		return ""
+	}
+
+	name, _ := resolveStaticPath(w.root, strings.TrimPrefix(u.Path, w.root))
+
+	return name
+}
+
+// scan records the modification time of every file in the directory
+func (w *Watcher) scan() (map[string]time.Time, error) {
+
+	modTimes := make(map[string]time.Time)
+
+	err := filepath.WalkDir(w.root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		modTimes[name] = info.ModTime()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to scan %s, %s", w.dir, err)
+	}
+
+	return modTimes, nil
+}
+
+// recheck crawls the given pages again, treating the crawled links as
+// already checked so that only the pages and any new links on them are
+// requested
+func (l *LinkChecker) recheck(site string, pages map[string]string, crawled []string) error {
+
+	canonicalSite, err := l.setSite(site)
+	if err != nil {
+		close(l.results)
+		return err
+	}
+
+	for _, link := range crawled {
+		l.AddSite(link)
+	}
+
+	l.startSummary(canonicalSite)
+
+	for page, referringSite := range pages {
+		l.wg.Add(1)
+		go l.Crawl(page, referringSite)
+	}
+	l.wg.Wait()
+
+	close(l.results)
+
+	l.finishSummary()
+
+	if l.cache != nil {
+		err = l.cache.Save()
+		if err != nil {
+			return fmt.Errorf("unable to save result cache, %s", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package linkchecker_test
+
+import (
+	"io"
+	"linkchecker"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWatcher(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	modified := time.Now().Add(-time.Hour)
+	write := func(name, content string) {
+		t.Helper()
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// step the modification time so changes are seen on any filesystem
+		modified = modified.Add(time.Second)
+		err = os.Chtimes(filepath.Join(dir, name), modified, modified)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("index.html", `<a href="/about">about</a><a href="/docs">docs</a>`)
+	write("about.html", `<a href="/missing">missing</a>`)
+	write("docs.html", `docs`)
+
+	var mutex sync.Mutex
+	requested := map[string]int{}
+
+	count := func(next http.RoundTripper) http.RoundTripper {
+		return linkchecker.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			mutex.Lock()
+			requested[r.URL.Path[strings.LastIndex(r.URL.Path, "/"):]]++
+			mutex.Unlock()
+			return next.RoundTrip(r)
+		})
+	}
+
+	w, err := linkchecker.NewWatcher(dir,
+		linkchecker.WithOutput(io.Discard),
+		linkchecker.WithMiddleware(count),
+		linkchecker.WithConfigureRatelimiter(1000, 1000),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = w.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	site, err := linkchecker.FileUrl(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []linkchecker.Result{
+		{ResponseCode: http.StatusNotFound, Url: site + "/missing", ReferringSite: site + "/about", Status: linkchecker.StatusDown, Problem: "Non OK response"},
+	}
+
+	got := w.Results()
+
+	if !cmp.Equal(want, got, ignoreMetrics, ignoreReferrers) {
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics, ignoreReferrers))
+	}
+
+	mutex.Lock()
+	requested = map[string]int{}
+	mutex.Unlock()
+
+	// fixing the page re-checks it and the page linking to it
+	write("about.html", `<a href="/new">new</a>`)
+
+	checked, err := w.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal([]string{site, site + "/about"}, checked) {
+		t.Fatal(cmp.Diff([]string{site, site + "/about"}, checked))
+	}
+
+	if requested["/docs"] > 0 {
+		t.Fatal("want unchanged page not to be requested again")
+	}
+
+	want = []linkchecker.Result{
+		{ResponseCode: http.StatusNotFound, Url: site + "/new", ReferringSite: site + "/about", Status: linkchecker.StatusDown, Problem: "Non OK response"},
+	}
+
+	got = w.Results()
+
+	if !cmp.Equal(want, got, ignoreMetrics, ignoreReferrers) {
+		t.Fatal(cmp.Diff(want, got, ignoreMetrics, ignoreReferrers))
+	}
+
+	// creating the missing page fixes the link
+	write("new.html", `new`)
+
+	_, err = w.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got = w.Results()
+
+	if len(got) != 0 {
+		t.Fatalf("want no broken links, got: %v", got)
+	}
+
+}